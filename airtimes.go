@@ -0,0 +1,93 @@
+package tablometadata
+
+import (
+	"time"
+)
+
+// In returns the air date as wall-clock time in loc. Use time.LoadLocation to
+// get a zone such as "America/Chicago"; daylight saving time is applied for
+// the date itself, not for the date it is converted on. A nil loc is UTC, as
+// it is for every function taking a location in this package.
+func (tt TabloDate) In(loc *time.Location) time.Time {
+	return tt.StoredTime.In(locationOrUTC(loc))
+}
+
+// LocalDate returns the calendar date of the air date in loc.
+func (tt TabloDate) LocalDate(loc *time.Location) CivilDate {
+	return CivilDateOf(tt.In(loc))
+}
+
+// RecordingWindow is when a recording was scheduled and when the tuner
+// actually captured it, all in one location. Start and End include the
+// padding from VideoInfo.ScheduleOffsetStart and ScheduleOffsetEnd.
+type RecordingWindow struct {
+	ScheduledStart time.Time
+	ScheduledEnd   time.Time
+	Start          time.Time
+	End            time.Time
+}
+
+// Scheduled returns the length of the program as listed in the guide.
+func (rw RecordingWindow) Scheduled() time.Duration {
+	return rw.ScheduledEnd.Sub(rw.ScheduledStart)
+}
+
+// Duration returns the length of the window that was recorded. It is elapsed
+// time, so a window spanning a daylight saving change is not off by an hour.
+func (rw RecordingWindow) Duration() time.Duration {
+	return rw.End.Sub(rw.Start)
+}
+
+// RecordingWindow computes the window from AirDate, ScheduleDuration and the
+// video's schedule offsets, converted to loc. The offsets are added to the
+// instant rather than to the wall clock, so windows across a daylight saving
+// transition come out right. It returns false when there is no AirDate.
+func (tr ClientJSON) RecordingWindow(loc *time.Location) (RecordingWindow, bool) {
+	if tr.AirDate.StoredTime.IsZero() {
+		return RecordingWindow{}, false
+	}
+	loc = locationOrUTC(loc)
+	scheduledStart := tr.AirDate.StoredTime
+	scheduledEnd := scheduledStart.Add(secondsDuration(tr.ScheduleDuration))
+	return RecordingWindow{
+		ScheduledStart: scheduledStart.In(loc),
+		ScheduledEnd:   scheduledEnd.In(loc),
+		Start:          scheduledStart.Add(secondsDuration(tr.Video.ScheduleOffsetStart)).In(loc),
+		End:            scheduledEnd.Add(secondsDuration(tr.Video.ScheduleOffsetEnd)).In(loc),
+	}, true
+}
+
+// AirTimeIn returns the air date of the recorded object in loc; see
+// TabloDate.In.
+func (tr Recording) AirTimeIn(loc *time.Location) (time.Time, bool) {
+	primary := tr.Primary()
+	if primary == nil {
+		return time.Time{}, false
+	}
+	airTime, wasFound := primary.AirTime()
+	if !wasFound {
+		return time.Time{}, false
+	}
+	return airTime.In(locationOrUTC(loc)), true
+}
+
+// RecordingWindow returns the window of the recorded object; see
+// ClientJSON.RecordingWindow.
+func (tr Recording) RecordingWindow(loc *time.Location) (RecordingWindow, bool) {
+	primary := tr.Primary()
+	if primary == nil {
+		return RecordingWindow{}, false
+	}
+	return clientOf(primary).RecordingWindow(loc)
+}
+
+func locationOrUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func secondsDuration(seconds float32) time.Duration {
+	return time.Duration(float64(seconds) * float64(time.Second))
+}
@@ -0,0 +1,110 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestAirTimeIn(t *testing.T) {
+	central := loadLocation(t, "America/Chicago")
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+
+	airTime, wasFound := recording.AirTimeIn(central)
+	if !wasFound {
+		t.Fatal("expected an air time")
+	}
+	if formatted := airTime.Format("Monday 3 PM MST"); formatted != "Tuesday 12 AM CDT" {
+		t.Errorf("unexpected local air time %s", formatted)
+	}
+	if localDate := recording.RecordedEpisode.JSONForClient.AirDate.LocalDate(central); localDate.String() != "2017-09-19" {
+		t.Errorf("unexpected local date %s", localDate)
+	}
+
+	winter := strings.Replace(sampleEpisodeJSON, `"airDate":"2017-09-19T05:00Z"`, `"airDate":"2018-01-16T03:00Z"`, 1)
+	if err := json.Unmarshal([]byte(winter), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if airTime, _ := recording.AirTimeIn(central); airTime.Format("Monday 3 PM MST") != "Monday 9 PM CST" {
+		t.Errorf("expected standard time in January, got %s", airTime.Format("Monday 3 PM MST"))
+	}
+}
+
+func TestNilLocationIsUTC(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+
+	airTime, wasFound := recording.AirTimeIn(nil)
+	if !wasFound || airTime.Location() != time.UTC || airTime.Format(time.RFC3339) != "2017-09-19T05:00:00Z" {
+		t.Errorf("expected the air time in UTC, got %s", airTime)
+	}
+	if inUTC := recording.RecordedEpisode.JSONForClient.AirDate.In(nil); !inUTC.Equal(airTime) || inUTC.Location() != time.UTC {
+		t.Errorf("expected TabloDate.In(nil) to use UTC, got %s", inUTC)
+	}
+	window, wasFound := recording.RecordingWindow(nil)
+	if !wasFound || window.Start.Location() != time.UTC || window.End.Location() != time.UTC {
+		t.Errorf("expected the window in UTC, got %+v", window)
+	}
+	if midnight := recording.RecordedEpisode.JSONForClient.AirDate.LocalDate(nil).Time(nil); midnight.Format(time.RFC3339) != "2017-09-19T00:00:00Z" {
+		t.Errorf("expected midnight UTC, got %s", midnight)
+	}
+}
+
+func TestRecordingWindow(t *testing.T) {
+	central := loadLocation(t, "America/Chicago")
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+
+	window, wasFound := recording.RecordingWindow(central)
+	if !wasFound {
+		t.Fatal("expected a recording window")
+	}
+	if window.Start.Format("15:04:05 MST") != "23:59:45 CDT" || window.End.Format("15:04:05 MST") != "01:30:05 CDT" {
+		t.Errorf("unexpected window %v - %v", window.Start, window.End)
+	}
+	if window.Scheduled() != time.Hour || window.Duration() != 5420*time.Second {
+		t.Errorf("unexpected durations %v and %v", window.Scheduled(), window.Duration())
+	}
+
+	var empty tablometadata.Recording
+	if _, wasFound := empty.RecordingWindow(central); wasFound {
+		t.Error("expected no window for an empty recording")
+	}
+}
+
+func TestRecordingWindowAcrossDaylightSavingChange(t *testing.T) {
+	central := loadLocation(t, "America/Chicago")
+	client := tablometadata.ClientJSON{Type: "recEpisode", ScheduleDuration: 3600,
+		Video: tablometadata.VideoInfo{ScheduleOffsetStart: -60, ScheduleOffsetEnd: 60}}
+	if err := json.Unmarshal([]byte(`"2017-11-05T06:00Z"`), &client.AirDate); err != nil {
+		t.Fatal(err)
+	}
+
+	window, _ := client.RecordingWindow(central)
+	if window.ScheduledStart.Format("15:04 MST") != "01:00 CDT" || window.ScheduledEnd.Format("15:04 MST") != "01:00 CST" {
+		t.Errorf("unexpected schedule %v - %v", window.ScheduledStart, window.ScheduledEnd)
+	}
+	if window.Scheduled() != time.Hour || window.Duration() != time.Hour+2*time.Minute {
+		t.Errorf("unexpected durations %v and %v", window.Scheduled(), window.Duration())
+	}
+}
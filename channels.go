@@ -0,0 +1,119 @@
+package tablometadata
+
+// ChannelInfo is the station a recChannel describes.
+type ChannelInfo struct {
+	CallSign string `json:"callSign"`
+	Major    int    `json:"major"`
+	Minor    int    `json:"minor"`
+
+	layout *objectLayout
+}
+
+func (ci *ChannelInfo) setLayout(layout *objectLayout) {
+	ci.layout = layout
+}
+
+func (ci ChannelInfo) MarshalJSON() ([]byte, error) {
+	if ci.layout != nil {
+		return encodeWithLayout(ci.layout, ci)
+	}
+	encoder := newObjectEncoder(ci)
+	encoder.String("CallSign", ci.CallSign)
+	encoder.Int("Major", ci.Major)
+	encoder.Int("Minor", ci.Minor)
+	return encoder.Bytes()
+}
+
+// ChannelClient is the jsonForClient of a recChannel. Like SportEventClient it
+// is the storage type of its wrapper, so the channel fields stay out of
+// ClientJSON.
+type ChannelClient struct {
+	Type     string      `json:"type"`
+	Channel  ChannelInfo `json:"channel"`
+	ObjectID int         `json:"objectID"`
+
+	layout *objectLayout
+}
+
+func (cc *ChannelClient) setLayout(layout *objectLayout) {
+	cc.layout = layout
+}
+
+func (cc *ChannelClient) clientType() string {
+	return "recChannel"
+}
+
+func (cc *ChannelClient) UnmarshalJSON(data []byte) error {
+	type plain ChannelClient
+	return decodeClient(data, cc, (*plain)(cc), cc.layout)
+}
+
+func (cc ChannelClient) MarshalJSON() ([]byte, error) {
+	if cc.layout != nil {
+		return encodeWithLayout(cc.layout, cc)
+	}
+	encoder := newObjectEncoder(cc)
+	encoder.String("Type", cc.Type)
+	encoder.Value("Channel", cc.Channel)
+	encoder.Int("ObjectID", cc.ObjectID)
+	return encoder.Bytes()
+}
+
+func (cc ChannelClient) Validate() error {
+	vd := &validator{}
+	vd.check(cc.ObjectID > 0, "objectID", "must be positive, got %d", cc.ObjectID)
+	vd.check(cc.Channel.Major >= 0, "channel.major", "must not be negative, got %d", cc.Channel.Major)
+	vd.check(cc.Channel.Minor >= 0, "channel.minor", "must not be negative, got %d", cc.Channel.Minor)
+	return vd.err()
+}
+
+func (cc ChannelClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recChannel", Title: cc.Channel.CallSign, ObjectID: cc.ObjectID}
+}
+
+type RecChannel struct {
+	JSONForClient ChannelClient `json:"jsonForClient"`
+
+	layout *objectLayout
+}
+
+func (rc *RecChannel) setLayout(layout *objectLayout) {
+	rc.layout = layout
+}
+
+func (rc RecChannel) MarshalJSON() ([]byte, error) {
+	if rc.layout != nil {
+		return encodeWithLayout(rc.layout, rc)
+	}
+	encoder := newObjectEncoder(rc)
+	encoder.Value("JSONForClient", rc.JSONForClient)
+	return encoder.Bytes()
+}
+
+func (rc *RecChannel) GetTabloType() string {
+	return rc.JSONForClient.Type
+}
+
+func (rc *RecChannel) ObjectID() int {
+	return rc.JSONForClient.ObjectID
+}
+
+func (rc *RecChannel) DisplayTitle() string {
+	return rc.JSONForClient.Channel.CallSign
+}
+
+func (rc *RecChannel) Relationships() Relationships {
+	return Relationships{}
+}
+
+func (rc *RecChannel) Images() []ImageData {
+	return nil
+}
+
+func (rc *RecChannel) AirTime() (TabloDate, bool) {
+	return TabloDate{}, false
+}
+
+func (rc *RecChannel) client() ClientJSON {
+	return rc.JSONForClient.ClientJSON()
+}
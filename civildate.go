@@ -0,0 +1,184 @@
+package tablometadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const CIVILDATELAYOUT = "2006-01-02"
+
+// CivilDate is a date without a time of day or zone, such as the
+// originalAirDate Tablo writes as "2017-09-18". It keeps the text it was read
+// from and writes it back unchanged. The zero CivilDate is empty; text that is
+// not a date is kept as well but is also treated as an unknown date.
+type CivilDate struct {
+	year  int
+	month time.Month
+	day   int
+	known bool
+	text  string
+}
+
+// NewCivilDate returns the date for year, month and day, normalized the way
+// time.Date normalizes them.
+func NewCivilDate(year int, month time.Month, day int) CivilDate {
+	return CivilDateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// CivilDateOf returns the date of t in t's location.
+func CivilDateOf(t time.Time) CivilDate {
+	year, month, day := t.Date()
+	return CivilDate{year: year, month: month, day: day, known: true, text: t.Format(CIVILDATELAYOUT)}
+}
+
+// ParseCivilDate parses a date in CIVILDATELAYOUT. An empty text gives the
+// empty CivilDate.
+func ParseCivilDate(text string) (CivilDate, error) {
+	if len(text) < 1 {
+		return CivilDate{}, nil
+	}
+	parsed, err := time.Parse(CIVILDATELAYOUT, text)
+	if err != nil {
+		return CivilDate{}, &ParseError{Value: text, Err: ErrUnknownDatePattern}
+	}
+	civilDate := CivilDateOf(parsed)
+	civilDate.text = text
+	return civilDate, nil
+}
+
+// IsZero reports whether cd holds no known date, either because it is empty or
+// because its text is not a date.
+func (cd CivilDate) IsZero() bool {
+	return !cd.known
+}
+
+func (cd CivilDate) Date() (int, time.Month, int) {
+	return cd.year, cd.month, cd.day
+}
+
+// Time returns midnight at the start of cd in loc, or the zero time for an
+// unknown date. A nil loc is UTC.
+func (cd CivilDate) Time(loc *time.Location) time.Time {
+	if !cd.known {
+		return time.Time{}
+	}
+	return time.Date(cd.year, cd.month, cd.day, 0, 0, 0, 0, locationOrUTC(loc))
+}
+
+// AddDays returns the date days after cd. An unknown date stays unknown.
+func (cd CivilDate) AddDays(days int) CivilDate {
+	if !cd.known {
+		return cd
+	}
+	return NewCivilDate(cd.year, cd.month, cd.day+days)
+}
+
+// DaysUntil returns the number of days from cd to other, negative when other
+// is earlier. It is 0 when either date is unknown.
+func (cd CivilDate) DaysUntil(other CivilDate) int {
+	if !cd.known || !other.known {
+		return 0
+	}
+	return int(other.Time(time.UTC).Sub(cd.Time(time.UTC)).Hours() / 24)
+}
+
+// Compare returns -1, 0 or 1 as cd is before, the same as or after other.
+// Unknown dates sort before every known date and compare equal to each other.
+func (cd CivilDate) Compare(other CivilDate) int {
+	switch {
+	case !cd.known || !other.known:
+		switch {
+		case cd.known:
+			return 1
+		case other.known:
+			return -1
+		}
+		return 0
+	case cd.year != other.year:
+		return compareInts(cd.year, other.year)
+	case cd.month != other.month:
+		return compareInts(int(cd.month), int(other.month))
+	}
+	return compareInts(cd.day, other.day)
+}
+
+func (cd CivilDate) Before(other CivilDate) bool {
+	return cd.known && other.known && cd.Compare(other) < 0
+}
+
+func (cd CivilDate) After(other CivilDate) bool {
+	return cd.known && other.known && cd.Compare(other) > 0
+}
+
+// Equal reports whether cd and other are the same known date, regardless of
+// the text they were read from.
+func (cd CivilDate) Equal(other CivilDate) bool {
+	return cd.known && other.known && cd.Compare(other) == 0
+}
+
+// String returns the text cd was read from, or CIVILDATELAYOUT for a date
+// built with NewCivilDate or CivilDateOf.
+func (cd CivilDate) String() string {
+	return cd.text
+}
+
+func (cd *CivilDate) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return &ParseError{Value: string(data), Err: fmt.Errorf("civil date must be a string: %w", err)}
+	}
+	civilDate, err := ParseCivilDate(text)
+	if err != nil {
+		// Old firmware writes placeholders here; keep them as unknown dates
+		// rather than failing the whole object.
+		civilDate = CivilDate{text: text}
+	}
+	*cd = civilDate
+	return nil
+}
+
+func (cd CivilDate) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := writeJSONString(&buffer, cd.text); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// IsFirstRun reports whether the airing is the original broadcast, by
+// comparing AirDate with OriginalAirDate. AirDate is in UTC while
+// OriginalAirDate is a local date, so an airing within a day of the original
+// date counts as the first run. The second result is false when either date is
+// missing.
+func (tr ClientJSON) IsFirstRun() (bool, bool) {
+	if tr.OriginalAirDate.IsZero() || tr.AirDate.StoredTime.IsZero() {
+		return false, false
+	}
+	days := tr.OriginalAirDate.DaysUntil(CivilDateOf(tr.AirDate.StoredTime.UTC()))
+	return days >= -1 && days <= 1, true
+}
+
+// IsFirstRun reports whether the recorded object is a first-run airing; see
+// ClientJSON.IsFirstRun.
+func (tr Recording) IsFirstRun() (bool, bool) {
+	primary := tr.Primary()
+	if primary == nil {
+		return false, false
+	}
+	return clientOf(primary).IsFirstRun()
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestCivilDateKeepsOriginalText(t *testing.T) {
+	for _, encoded := range []string{`"2017-09-18"`, `""`, `"TBA"`} {
+		var civilDate tablometadata.CivilDate
+		if err := json.Unmarshal([]byte(encoded), &civilDate); err != nil {
+			t.Fatalf("%s: %v", encoded, err)
+		}
+		jsonData, err := civilDate.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(jsonData) != encoded {
+			t.Errorf("expected %s, got %s", encoded, jsonData)
+		}
+	}
+
+	var civilDate tablometadata.CivilDate
+	if err := json.Unmarshal([]byte(`"TBA"`), &civilDate); err != nil || !civilDate.IsZero() || civilDate.String() != "TBA" {
+		t.Errorf("expected an unknown date keeping its text, got %v %v", civilDate, err)
+	}
+	if err := json.Unmarshal([]byte(`20170918`), &civilDate); err == nil {
+		t.Error("expected a number to be rejected")
+	}
+	if _, err := tablometadata.ParseCivilDate("2017-13-01"); err == nil {
+		t.Error("expected an invalid month to be rejected")
+	}
+}
+
+func TestCivilDateComparisons(t *testing.T) {
+	premiere := tablometadata.NewCivilDate(2017, time.July, 24)
+	finale, err := tablometadata.ParseCivilDate("2017-09-18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unknown tablometadata.CivilDate
+
+	if !premiere.Before(finale) || !finale.After(premiere) || premiere.Equal(finale) {
+		t.Error("expected the premiere before the finale")
+	}
+	if unknown.Before(premiere) || unknown.After(premiere) || unknown.Equal(unknown) {
+		t.Error("expected an unknown date to compare with nothing")
+	}
+	if days := premiere.DaysUntil(finale); days != 56 {
+		t.Errorf("expected 56 days, got %d", days)
+	}
+	if next := tablometadata.NewCivilDate(2017, time.December, 31).AddDays(1); next.String() != "2018-01-01" {
+		t.Errorf("expected the next year, got %s", next)
+	}
+	if !tablometadata.NewCivilDate(2017, time.September, 18).Equal(finale) {
+		t.Error("expected equal dates built different ways to be equal")
+	}
+
+	dates := []tablometadata.CivilDate{finale, unknown, premiere}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Compare(dates[j]) < 0 })
+	if !dates[0].IsZero() || dates[1] != premiere || !dates[2].Equal(finale) {
+		t.Errorf("unexpected order %v", dates)
+	}
+}
+
+func TestIsFirstRun(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if firstRun, wasKnown := recording.IsFirstRun(); !firstRun || !wasKnown {
+		t.Errorf("expected a first run, got %v %v", firstRun, wasKnown)
+	}
+
+	rerun := strings.Replace(sampleEpisodeJSON, `"airDate":"2017-09-19T05:00Z"`, `"airDate":"2018-02-01T05:00Z"`, 1)
+	if err := json.Unmarshal([]byte(rerun), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if firstRun, wasKnown := recording.IsFirstRun(); firstRun || !wasKnown {
+		t.Errorf("expected a rerun, got %v %v", firstRun, wasKnown)
+	}
+
+	var movie tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleMovieJSON), &movie); err != nil {
+		t.Fatal(err)
+	}
+	if _, wasKnown := movie.IsFirstRun(); wasKnown {
+		t.Error("expected a movie airing without an originalAirDate to be unknown")
+	}
+}
@@ -0,0 +1,383 @@
+package tablometadata
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The typed clients below each hold only the jsonForClient fields the Tablo
+// writes for one object type. ClientJSON stays the storage type of the object
+// wrappers; use its MovieClient, EpisodeClient, ... methods and the clients'
+// ClientJSON methods to move between the two.
+
+type MovieClient struct {
+	Title         string        `json:"title"`
+	Plot          string        `json:"plot"`
+	Runtime       int           `json:"runtime"`
+	MPAARating    string        `json:"mpaaRating"`
+	ReleaseYear   int           `json:"releaseYear"`
+	Cast          []string      `json:"cast"`
+	Directors     []string      `json:"directors"`
+	QualityRating float32       `json:"qualityRating"`
+	Relationships Relationships `json:"relationships"`
+	ObjectID      int           `json:"objectID"`
+
+	layout *objectLayout
+}
+
+type MovieAiringClient struct {
+	ObjectID         int           `json:"objectID"`
+	AirDate          TabloDate     `json:"airDate"`
+	ScheduleDuration float32       `json:"scheduleDuration"`
+	Relationships    Relationships `json:"relationships"`
+	Video            VideoInfo     `json:"video"`
+	User             UserInfo      `json:"user"`
+
+	layout *objectLayout
+}
+
+type EpisodeClient struct {
+	Title            string        `json:"title"`
+	Description      string        `json:"description"`
+	EpisodeNumber    int           `json:"episodeNumber"`
+	SeasonNumber     int           `json:"seasonNumber"`
+	AirDate          TabloDate     `json:"airDate"`
+	OriginalAirDate  CivilDate     `json:"originalAirDate"`
+	ScheduleDuration float32       `json:"scheduleDuration"`
+	Qualifiers       []string      `json:"qualifiers"`
+	Relationships    Relationships `json:"relationships"`
+	Video            VideoInfo     `json:"video"`
+	User             UserInfo      `json:"user"`
+	ObjectID         int           `json:"objectID"`
+
+	layout *objectLayout
+}
+
+type SeriesClient struct {
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	OriginalAirDate CivilDate     `json:"originalAirDate"`
+	Duration        int           `json:"duration"`
+	Cast            []string      `json:"cast"`
+	Relationships   Relationships `json:"relationships"`
+	ObjectID        int           `json:"objectID"`
+
+	layout *objectLayout
+}
+
+type SeasonClient struct {
+	SeasonNumber  int           `json:"seasonNumber"`
+	Relationships Relationships `json:"relationships"`
+	ObjectID      int           `json:"objectID"`
+
+	layout *objectLayout
+}
+
+func (mc *MovieClient) setLayout(layout *objectLayout) {
+	mc.layout = layout
+}
+
+func (mc *MovieClient) clientType() string {
+	return "recMovie"
+}
+
+func (mc *MovieClient) UnmarshalJSON(data []byte) error {
+	type plain MovieClient
+	return decodeClient(data, mc, (*plain)(mc), mc.layout)
+}
+
+func (mc MovieClient) MarshalJSON() ([]byte, error) {
+	if mc.layout != nil {
+		return encodeWithLayout(mc.layout, mc)
+	}
+	encoder := newObjectEncoder(mc)
+	encoder.String("Title", mc.Title)
+	encoder.String("Plot", mc.Plot)
+	encoder.Int("Runtime", mc.Runtime)
+	encoder.String("MPAARating", mc.MPAARating)
+	encoder.Int("ReleaseYear", mc.ReleaseYear)
+	encoder.Value("Cast", mc.Cast)
+	encoder.Value("Directors", mc.Directors)
+	encoder.Float("QualityRating", mc.QualityRating, 3)
+	encoder.Value("Relationships", mc.Relationships)
+	encoder.TypeName("recMovie")
+	encoder.Int("ObjectID", mc.ObjectID)
+	return encoder.Bytes()
+}
+
+func (mc MovieClient) Validate() error {
+	vd := &validator{}
+	vd.check(mc.ObjectID > 0, "objectID", "must be positive, got %d", mc.ObjectID)
+	vd.check(len(mc.Title) > 0, "title", "must not be empty")
+	vd.check(mc.Runtime >= 0, "runtime", "must not be negative, got %d", mc.Runtime)
+	vd.check(mc.ReleaseYear >= 0, "releaseYear", "must not be negative, got %d", mc.ReleaseYear)
+	vd.check(mc.QualityRating >= 0, "qualityRating", "must not be negative, got %.3f", mc.QualityRating)
+	return vd.err()
+}
+
+func (mc MovieClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recMovie", Title: mc.Title, Plot: mc.Plot, Runtime: mc.Runtime, MPAARating: mc.MPAARating,
+		ReleaseYear: mc.ReleaseYear, Cast: mc.Cast, Directors: mc.Directors, QualityRating: mc.QualityRating,
+		Relationships: mc.Relationships, ObjectID: mc.ObjectID, layout: mc.layout}
+}
+
+func (tr ClientJSON) MovieClient() MovieClient {
+	return MovieClient{Title: tr.Title, Plot: tr.Plot, Runtime: tr.Runtime, MPAARating: tr.MPAARating,
+		ReleaseYear: tr.ReleaseYear, Cast: tr.Cast, Directors: tr.Directors, QualityRating: tr.QualityRating,
+		Relationships: tr.Relationships, ObjectID: tr.ObjectID, layout: tr.layout}
+}
+
+func (mac *MovieAiringClient) setLayout(layout *objectLayout) {
+	mac.layout = layout
+}
+
+func (mac *MovieAiringClient) clientType() string {
+	return "recMovieAiring"
+}
+
+func (mac *MovieAiringClient) UnmarshalJSON(data []byte) error {
+	type plain MovieAiringClient
+	return decodeClient(data, mac, (*plain)(mac), mac.layout)
+}
+
+func (mac MovieAiringClient) MarshalJSON() ([]byte, error) {
+	if mac.layout != nil {
+		return encodeWithLayout(mac.layout, mac)
+	}
+	encoder := newObjectEncoder(mac)
+	encoder.TypeName("recMovieAiring")
+	encoder.Int("ObjectID", mac.ObjectID)
+	encoder.Value("AirDate", mac.AirDate)
+	encoder.Float("ScheduleDuration", mac.ScheduleDuration, 1)
+	encoder.Value("Relationships", mac.Relationships)
+	encoder.Value("Video", mac.Video)
+	encoder.Value("User", mac.User)
+	return encoder.Bytes()
+}
+
+func (mac MovieAiringClient) Validate() error {
+	vd := &validator{}
+	vd.check(mac.ObjectID > 0, "objectID", "must be positive, got %d", mac.ObjectID)
+	vd.check(!mac.AirDate.StoredTime.IsZero(), "airDate", "must be set")
+	vd.check(mac.ScheduleDuration >= 0, "scheduleDuration", "must not be negative, got %.1f", mac.ScheduleDuration)
+	vd.check(mac.Relationships.RecMovie > 0, "relationships.recMovie", "must link the airing to a movie")
+	vd.nested("video.", mac.Video.validate)
+	vd.nested("user.", mac.User.validate)
+	return vd.err()
+}
+
+func (mac MovieAiringClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recMovieAiring", ObjectID: mac.ObjectID, AirDate: mac.AirDate, ScheduleDuration: mac.ScheduleDuration,
+		Relationships: mac.Relationships, Video: mac.Video, User: mac.User, layout: mac.layout}
+}
+
+func (tr ClientJSON) MovieAiringClient() MovieAiringClient {
+	return MovieAiringClient{ObjectID: tr.ObjectID, AirDate: tr.AirDate, ScheduleDuration: tr.ScheduleDuration,
+		Relationships: tr.Relationships, Video: tr.Video, User: tr.User, layout: tr.layout}
+}
+
+func (ec *EpisodeClient) setLayout(layout *objectLayout) {
+	ec.layout = layout
+}
+
+func (ec *EpisodeClient) clientType() string {
+	return "recEpisode"
+}
+
+func (ec *EpisodeClient) UnmarshalJSON(data []byte) error {
+	type plain EpisodeClient
+	return decodeClient(data, ec, (*plain)(ec), ec.layout)
+}
+
+func (ec EpisodeClient) MarshalJSON() ([]byte, error) {
+	if ec.layout != nil {
+		return encodeWithLayout(ec.layout, ec)
+	}
+	encoder := newObjectEncoder(ec)
+	encoder.TypeName("recEpisode")
+	encoder.String("Title", ec.Title)
+	encoder.String("Description", ec.Description)
+	encoder.Int("EpisodeNumber", ec.EpisodeNumber)
+	encoder.Int("SeasonNumber", ec.SeasonNumber)
+	encoder.Value("AirDate", ec.AirDate)
+	encoder.Value("OriginalAirDate", ec.OriginalAirDate)
+	encoder.Float("ScheduleDuration", ec.ScheduleDuration, 0)
+	encoder.Value("Qualifiers", ec.Qualifiers)
+	encoder.Value("Relationships", ec.Relationships)
+	encoder.Value("Video", ec.Video)
+	encoder.Value("User", ec.User)
+	encoder.Int("ObjectID", ec.ObjectID)
+	return encoder.Bytes()
+}
+
+func (ec EpisodeClient) Validate() error {
+	vd := &validator{}
+	vd.check(ec.ObjectID > 0, "objectID", "must be positive, got %d", ec.ObjectID)
+	vd.check(ec.EpisodeNumber >= 0, "episodeNumber", "must not be negative, got %d", ec.EpisodeNumber)
+	vd.check(ec.SeasonNumber >= 0, "seasonNumber", "must not be negative, got %d", ec.SeasonNumber)
+	vd.check(!ec.AirDate.StoredTime.IsZero(), "airDate", "must be set")
+	vd.check(ec.ScheduleDuration >= 0, "scheduleDuration", "must not be negative, got %.0f", ec.ScheduleDuration)
+	vd.check(ec.Relationships.RecSeries > 0, "relationships.recSeries", "must link the episode to a series")
+	vd.nested("video.", ec.Video.validate)
+	vd.nested("user.", ec.User.validate)
+	return vd.err()
+}
+
+func (ec EpisodeClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recEpisode", Title: ec.Title, Description: ec.Description, EpisodeNumber: ec.EpisodeNumber,
+		SeasonNumber: ec.SeasonNumber, AirDate: ec.AirDate, OriginalAirDate: ec.OriginalAirDate, ScheduleDuration: ec.ScheduleDuration,
+		Qualifiers: ec.Qualifiers, Relationships: ec.Relationships, Video: ec.Video, User: ec.User, ObjectID: ec.ObjectID, layout: ec.layout}
+}
+
+func (tr ClientJSON) EpisodeClient() EpisodeClient {
+	return EpisodeClient{Title: tr.Title, Description: tr.Description, EpisodeNumber: tr.EpisodeNumber,
+		SeasonNumber: tr.SeasonNumber, AirDate: tr.AirDate, OriginalAirDate: tr.OriginalAirDate, ScheduleDuration: tr.ScheduleDuration,
+		Qualifiers: tr.Qualifiers, Relationships: tr.Relationships, Video: tr.Video, User: tr.User, ObjectID: tr.ObjectID, layout: tr.layout}
+}
+
+func (sc *SeriesClient) setLayout(layout *objectLayout) {
+	sc.layout = layout
+}
+
+func (sc *SeriesClient) clientType() string {
+	return "recSeries"
+}
+
+func (sc *SeriesClient) UnmarshalJSON(data []byte) error {
+	type plain SeriesClient
+	return decodeClient(data, sc, (*plain)(sc), sc.layout)
+}
+
+func (sc SeriesClient) MarshalJSON() ([]byte, error) {
+	if sc.layout != nil {
+		return encodeWithLayout(sc.layout, sc)
+	}
+	encoder := newObjectEncoder(sc)
+	encoder.String("Title", sc.Title)
+	encoder.String("Description", sc.Description)
+	encoder.Value("OriginalAirDate", sc.OriginalAirDate)
+	encoder.Int("Duration", sc.Duration)
+	encoder.Value("Cast", sc.Cast)
+	encoder.Value("Relationships", sc.Relationships)
+	encoder.Int("ObjectID", sc.ObjectID)
+	encoder.TypeName("recSeries")
+	return encoder.Bytes()
+}
+
+func (sc SeriesClient) Validate() error {
+	vd := &validator{}
+	vd.check(sc.ObjectID > 0, "objectID", "must be positive, got %d", sc.ObjectID)
+	vd.check(len(sc.Title) > 0, "title", "must not be empty")
+	vd.check(sc.Duration >= 0, "duration", "must not be negative, got %d", sc.Duration)
+	return vd.err()
+}
+
+func (sc SeriesClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recSeries", Title: sc.Title, Description: sc.Description, OriginalAirDate: sc.OriginalAirDate,
+		Duration: sc.Duration, Cast: sc.Cast, Relationships: sc.Relationships, ObjectID: sc.ObjectID, layout: sc.layout}
+}
+
+func (tr ClientJSON) SeriesClient() SeriesClient {
+	return SeriesClient{Title: tr.Title, Description: tr.Description, OriginalAirDate: tr.OriginalAirDate,
+		Duration: tr.Duration, Cast: tr.Cast, Relationships: tr.Relationships, ObjectID: tr.ObjectID, layout: tr.layout}
+}
+
+func (sc *SeasonClient) setLayout(layout *objectLayout) {
+	sc.layout = layout
+}
+
+func (sc *SeasonClient) clientType() string {
+	return "recSeason"
+}
+
+func (sc *SeasonClient) UnmarshalJSON(data []byte) error {
+	type plain SeasonClient
+	return decodeClient(data, sc, (*plain)(sc), sc.layout)
+}
+
+func (sc SeasonClient) MarshalJSON() ([]byte, error) {
+	if sc.layout != nil {
+		return encodeWithLayout(sc.layout, sc)
+	}
+	encoder := newObjectEncoder(sc)
+	encoder.Int("SeasonNumber", sc.SeasonNumber)
+	encoder.Value("Relationships", sc.Relationships)
+	encoder.Int("ObjectID", sc.ObjectID)
+	encoder.TypeName("recSeason")
+	return encoder.Bytes()
+}
+
+func (sc SeasonClient) Validate() error {
+	vd := &validator{}
+	vd.check(sc.ObjectID > 0, "objectID", "must be positive, got %d", sc.ObjectID)
+	vd.check(sc.SeasonNumber >= 0, "seasonNumber", "must not be negative, got %d", sc.SeasonNumber)
+	vd.check(sc.Relationships.RecSeries > 0, "relationships.recSeries", "must link the season to a series")
+	return vd.err()
+}
+
+func (sc SeasonClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recSeason", SeasonNumber: sc.SeasonNumber, Relationships: sc.Relationships, ObjectID: sc.ObjectID, layout: sc.layout}
+}
+
+func (tr ClientJSON) SeasonClient() SeasonClient {
+	return SeasonClient{SeasonNumber: tr.SeasonNumber, Relationships: tr.Relationships, ObjectID: tr.ObjectID, layout: tr.layout}
+}
+
+// Validate checks tr with the rules of the typed client for its object type.
+// Types without a typed client only need a type and an object ID, and the
+// recorded ones among them, such as recProgram, valid video and user info.
+func (tr ClientJSON) Validate() error {
+	switch tr.Type {
+	case "recMovieAiring":
+		return tr.MovieAiringClient().Validate()
+	case "recMovie":
+		return tr.MovieClient().Validate()
+	case "recEpisode":
+		return tr.EpisodeClient().Validate()
+	case "recSeries":
+		return tr.SeriesClient().Validate()
+	case "recSeason":
+		return tr.SeasonClient().Validate()
+	}
+	vd := &validator{}
+	vd.check(len(tr.Type) > 0, "type", "must be set")
+	vd.check(tr.ObjectID > 0, "objectID", "must be positive, got %d", tr.ObjectID)
+	if isRecordedType(tr.Type) {
+		vd.check(tr.ScheduleDuration >= 0, "scheduleDuration", "must not be negative, got %.0f", tr.ScheduleDuration)
+		vd.nested("video.", tr.Video.validate)
+		vd.nested("user.", tr.User.validate)
+	}
+	return vd.err()
+}
+
+// typedClient is implemented by the typed clients, which only decode a
+// jsonForClient of their own type.
+type typedClient interface {
+	layoutHolder
+	clientType() string
+}
+
+// decodeClient decodes a typed client like decodeObject and rejects a
+// jsonForClient written for a different object type.
+func decodeClient(data []byte, target typedClient, plain interface{}, previous *objectLayout) error {
+	if err := checkClientType(data, target); err != nil {
+		return err
+	}
+	return decodeObject(data, target, plain, previous)
+}
+
+// checkClientType rejects a jsonForClient whose type is not target's. A missing
+// type is accepted.
+func checkClientType(data []byte, target typedClient) error {
+	tabloType := target.clientType()
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+	if len(envelope.Type) > 0 && envelope.Type != tabloType {
+		return fmt.Errorf("expected object type %q, got %q", tabloType, envelope.Type)
+	}
+	return nil
+}
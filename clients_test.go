@@ -0,0 +1,112 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestTypedClientsMatchClientJSON(t *testing.T) {
+	for _, sampleJSON := range []string{sampleMovieJSON, sampleEpisodeJSON} {
+		var recording tablometadata.Recording
+		if err := json.Unmarshal([]byte(sampleJSON), &recording); err != nil {
+			t.Fatal(err)
+		}
+		recording.ClearLayout()
+
+		for _, object := range recording.Objects() {
+			var client tablometadata.ClientJSON
+			switch typed := object.(type) {
+			case *tablometadata.RecMovie:
+				client = typed.JSONForClient
+			case *tablometadata.MovieAiring:
+				client = typed.JSONForClient
+			case *tablometadata.RecEpisode:
+				client = typed.JSONForClient
+			case *tablometadata.RecSeries:
+				client = typed.JSONForClient
+			case *tablometadata.RecSeason:
+				client = typed.JSONForClient
+			default:
+				continue
+			}
+			expected, err := client.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var typedJSON []byte
+			switch client.Type {
+			case "recMovie":
+				typedJSON, err = client.MovieClient().MarshalJSON()
+			case "recMovieAiring":
+				typedJSON, err = client.MovieAiringClient().MarshalJSON()
+			case "recEpisode":
+				typedJSON, err = client.EpisodeClient().MarshalJSON()
+			case "recSeries":
+				typedJSON, err = client.SeriesClient().MarshalJSON()
+			case "recSeason":
+				typedJSON, err = client.SeasonClient().MarshalJSON()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(typedJSON) != string(expected) {
+				t.Errorf("%s: typed client wrote %s, ClientJSON wrote %s", client.Type, typedJSON, expected)
+			}
+			if err := client.Validate(); err != nil {
+				t.Errorf("%s: unexpected validation error %v", client.Type, err)
+			}
+		}
+	}
+}
+
+func TestEpisodeClientDecode(t *testing.T) {
+	data := []byte(`{"type":"recEpisode","title":"Pilot","episodeNumber":1,"seasonNumber":1,"airDate":"2017-09-19T05:00Z","relationships":{"recSeason":2,"recSeries":1},"objectID":3}`)
+	var episode tablometadata.EpisodeClient
+	if err := tablometadata.UnmarshalLossless(data, &episode); err != nil {
+		t.Fatal(err)
+	}
+	if episode.Title != "Pilot" || episode.Relationships.RecSeason != 2 {
+		t.Errorf("unexpected episode %+v", episode)
+	}
+	jsonData, err := json.Marshal(episode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(jsonData) != string(data) {
+		t.Errorf("expected %s, got %s", data, jsonData)
+	}
+	if client := episode.ClientJSON(); client.Type != "recEpisode" || client.EpisodeNumber != 1 {
+		t.Errorf("unexpected ClientJSON %+v", client)
+	}
+
+	var movie tablometadata.MovieClient
+	if err := json.Unmarshal(data, &movie); err == nil {
+		t.Error("expected decoding an episode into a MovieClient to fail")
+	}
+	if err := tablometadata.UnmarshalLossless(data, &movie); err == nil {
+		t.Error("expected a lossless decode of an episode into a MovieClient to fail")
+	}
+}
+
+func TestTypedClientValidate(t *testing.T) {
+	season := tablometadata.SeasonClient{SeasonNumber: -1}
+	err := season.Validate()
+	validationErrors, isValidationErrors := err.(tablometadata.ValidationErrors)
+	if !isValidationErrors || len(validationErrors) != 3 {
+		t.Fatalf("expected three problems, got %v", err)
+	}
+
+	airing := tablometadata.MovieAiringClient{ObjectID: 1, Video: tablometadata.VideoInfo{Duration: -5}}
+	err = airing.Validate()
+	validationErrors, _ = err.(tablometadata.ValidationErrors)
+	var fields []string
+	for _, validationError := range validationErrors {
+		fields = append(fields, validationError.Field)
+	}
+	if len(fields) != 3 || fields[0] != "airDate" || fields[1] != "relationships.recMovie" || fields[2] != "video.duration" {
+		t.Errorf("unexpected problems %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Command tablometa reports on the recordings in a Tablo storage tree.
+//
+// Usage:
+//
+//	tablometa storage [-by series|season|channel|genre|year|all] [-top n] [-tz zone] [-lenient] <root>
+//
+// -tz names the time zone, such as America/Chicago, that decides which year a
+// recording made around midnight on New Year's Eve is counted in.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+	// -tz must work on systems without a zoneinfo database.
+	_ "time/tzdata"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "storage":
+		err = runStorage(os.Args[2:], os.Stdout, os.Stderr)
+	case "-h", "-help", "--help", "help":
+		usage(os.Stdout)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "tablometa: unknown command %q\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tablometa: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage(output io.Writer) {
+	fmt.Fprintln(output, "usage: tablometa <command> [arguments]")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "commands:")
+	fmt.Fprintln(output, "  storage    space used per series, season, channel, genre and year")
+}
+
+func runStorage(args []string, output io.Writer, errorOutput io.Writer) error {
+	flags := flag.NewFlagSet("storage", flag.ContinueOnError)
+	flags.SetOutput(errorOutput)
+	groupBy := flags.String("by", "all", "group by series, season, channel, genre, year or all")
+	top := flags.Int("top", 0, "show only the n largest entries of each group")
+	zone := flags.String("tz", "UTC", "time zone, such as America/Chicago, that recording years are counted in")
+	lenient := flags.Bool("lenient", false, "load meta files with malformed members instead of skipping them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("storage needs exactly one library root, got %d arguments", flags.NArg())
+	}
+	if !isStorageGrouping(*groupBy) {
+		return fmt.Errorf("unknown grouping %q", *groupBy)
+	}
+	if *top < 0 {
+		return fmt.Errorf("-top must not be negative, got %d", *top)
+	}
+	loc, err := time.LoadLocation(*zone)
+	if err != nil {
+		return fmt.Errorf("-tz: %v", err)
+	}
+
+	library, err := tablometadata.LoadLibraryWithOptions(context.Background(), flags.Arg(0), tablometadata.LoadOptions{Lenient: *lenient})
+	if err != nil {
+		return err
+	}
+	for _, loadError := range library.Errors {
+		fmt.Fprintf(errorOutput, "skipped %v\n", loadError)
+	}
+	return writeStorageReport(output, library.AnalyzeStorage(tablometadata.StorageOptions{Location: loc}), *groupBy, *top)
+}
+
+var storageGroupings = []string{"series", "season", "channel", "genre", "year"}
+
+func isStorageGrouping(groupBy string) bool {
+	if groupBy == "all" {
+		return true
+	}
+	for _, grouping := range storageGroupings {
+		if groupBy == grouping {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStorageReport prints the groups selected by groupBy as tables, largest
+// consumer first.
+func writeStorageReport(output io.Writer, report tablometadata.StorageReport, groupBy string, top int) error {
+	groups := []struct {
+		name    string
+		entries []tablometadata.StorageEntry
+	}{
+		{"series", report.BySeries},
+		{"season", report.BySeason},
+		{"channel", report.ByChannel},
+		{"genre", report.ByGenre},
+		{"year", report.ByYear},
+	}
+
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	wasWritten := false
+	for _, group := range groups {
+		if groupBy != "all" && groupBy != group.name {
+			continue
+		}
+		if wasWritten {
+			fmt.Fprintln(writer)
+		}
+		wasWritten = true
+		fmt.Fprintf(writer, "%s\trecordings\tsize\thours\tshare\twatched\n", group.name)
+		entries := group.entries
+		if top > 0 && len(entries) > top {
+			entries = entries[:top]
+		}
+		for _, entry := range entries {
+			writeStorageEntry(writer, report, entry)
+		}
+		writeStorageEntry(writer, report, report.Total)
+	}
+	if !wasWritten {
+		return fmt.Errorf("unknown grouping %q", groupBy)
+	}
+	return writer.Flush()
+}
+
+func writeStorageEntry(writer io.Writer, report tablometadata.StorageReport, entry tablometadata.StorageEntry) {
+	fmt.Fprintf(writer, "%s\t%d\t%s\t%.1f\t%.1f%%\t%.1f%%\n", entry.Label, entry.Recordings, formatBytes(entry.Bytes),
+		entry.Hours(), 100*report.Share(entry), 100*entry.WatchedShare())
+}
+
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	suffix := ""
+	for _, suffix = range suffixes {
+		value /= unit
+		if value < unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestWriteStorageReport(t *testing.T) {
+	report := tablometadata.StorageReport{
+		Total: tablometadata.StorageEntry{Label: "total", Recordings: 3, Bytes: 3 << 30, WatchedBytes: 1 << 30},
+		BySeries: []tablometadata.StorageEntry{
+			{ID: 1, Label: "Midnight, Texas", Recordings: 2, Bytes: 2 << 30},
+			{ID: 2, Label: "Nova", Recordings: 1, Bytes: 1 << 30, WatchedBytes: 1 << 30},
+		},
+	}
+
+	var output bytes.Buffer
+	if err := writeStorageReport(&output, report, "series", 1); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "series") || !strings.HasPrefix(lines[1], "Midnight, Texas") || !strings.HasPrefix(lines[2], "total") {
+		t.Fatalf("unexpected output\n%s", output.String())
+	}
+	if !strings.Contains(lines[1], "2.0 GiB") || !strings.Contains(lines[1], "66.7%") || !strings.Contains(lines[2], "33.3%") {
+		t.Errorf("unexpected columns\n%s", output.String())
+	}
+
+	if err := writeStorageReport(&output, report, "decade", 0); err == nil {
+		t.Error("expected an unknown grouping to fail")
+	}
+}
+
+func TestRunStorageValidatesFlagsBeforeLoading(t *testing.T) {
+	missingRoot := filepath.Join(t.TempDir(), "missing")
+	var output, errorOutput bytes.Buffer
+	err := runStorage([]string{"-by", "decade", missingRoot}, &output, &errorOutput)
+	if err == nil || !strings.Contains(err.Error(), `unknown grouping "decade"`) {
+		t.Errorf("expected the grouping to be rejected before loading, got %v", err)
+	}
+	if err := runStorage([]string{"-top", "-1", missingRoot}, &output, &errorOutput); err == nil || !strings.Contains(err.Error(), "-top") {
+		t.Errorf("expected a negative -top to be rejected, got %v", err)
+	}
+	if err := runStorage([]string{"-tz", "Mars/Olympus_Mons", missingRoot}, &output, &errorOutput); err == nil || !strings.Contains(err.Error(), "-tz") {
+		t.Errorf("expected an unknown -tz to be rejected, got %v", err)
+	}
+	if err := runStorage([]string{"-h"}, &output, &errorOutput); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp for -h, got %v", err)
+	}
+}
+
+func TestRunStorageCountsYearsInTimeZone(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, tablometadata.TABLORECDIR, "1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	newYear := `{"recMovieAiring":{"jsonForClient":{"type":"recMovieAiring","objectID":1,"airDate":"2017-01-01T03:00Z","video":{"state":"finished","size":1024}}}}`
+	if err := os.WriteFile(filepath.Join(dir, tablometadata.TABLOMETAFILE), []byte(newYear), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for zone, year := range map[string]string{"UTC": "2017", "America/Chicago": "2016"} {
+		var output, errorOutput bytes.Buffer
+		if err := runStorage([]string{"-by", "year", "-tz", zone, root}, &output, &errorOutput); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(output.String(), "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[1], year) {
+			t.Errorf("%s: expected the recording in %s, got\n%s", zone, year, output.String())
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for bytes, expected := range map[uint64]string{512: "512 B", 1536: "1.5 KiB", 5302616064: "4.9 GiB", 3 << 40: "3.0 TiB"} {
+		if formatted := formatBytes(bytes); formatted != expected {
+			t.Errorf("%d: expected %s, got %s", bytes, expected, formatted)
+		}
+	}
+}
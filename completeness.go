@@ -0,0 +1,155 @@
+package tablometadata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SeasonCompleteness is what was recorded of one season. Present lists the
+// episode numbers found and Missing the numbers between 1 and Highest that
+// were not; episodes after Highest cannot be detected. Unnumbered holds the
+// object IDs of episodes with episode number 0. Season 0 is the specials
+// season, whose numbering is too sparse to report gaps for.
+type SeasonCompleteness struct {
+	SeasonNumber int
+	SeasonID     int
+	Present      []int
+	Missing      []int
+	Highest      int
+	Unnumbered   []int
+}
+
+// Specials reports whether the season is the specials season.
+func (sc SeasonCompleteness) Specials() bool {
+	return sc.SeasonNumber == 0
+}
+
+// Complete reports whether every episode from 1 to Highest was recorded.
+func (sc SeasonCompleteness) Complete() bool {
+	return !sc.Specials() && sc.Highest > 0 && len(sc.Missing) == 0
+}
+
+func (sc SeasonCompleteness) String() string {
+	if sc.Specials() {
+		return fmt.Sprintf("specials: %d episodes", len(sc.Present)+len(sc.Unnumbered))
+	}
+	return fmt.Sprintf("season %d: %d of %d episodes, missing %v", sc.SeasonNumber, len(sc.Present), sc.Highest, sc.Missing)
+}
+
+// SeriesCompleteness is the per-season view of one series, seasons in order.
+type SeriesCompleteness struct {
+	SeriesID int
+	Title    string
+	Seasons  []SeasonCompleteness
+}
+
+// Season returns the view of seasonNumber.
+func (sc SeriesCompleteness) Season(seasonNumber int) (SeasonCompleteness, bool) {
+	for _, season := range sc.Seasons {
+		if season.SeasonNumber == seasonNumber {
+			return season, true
+		}
+	}
+	return SeasonCompleteness{}, false
+}
+
+// CompletenessReport lists every series with recorded episodes, ordered by
+// title and then series ID.
+type CompletenessReport struct {
+	Series []SeriesCompleteness
+}
+
+// AnalyzeCompleteness runs the package level AnalyzeCompleteness over every
+// recording in the library.
+func (tl *Library) AnalyzeCompleteness() CompletenessReport {
+	return AnalyzeCompleteness(tl.Recordings)
+}
+
+// AnalyzeCompleteness groups the recorded episodes by series and season and
+// reports which episode numbers are present and which are missing. The season
+// number comes from the episode, or from the bundled recSeason when the
+// episode does not carry one.
+func AnalyzeCompleteness(recordings []LibraryRecording) CompletenessReport {
+	type completenessSeasonKey struct {
+		seriesID     int
+		seasonNumber int
+	}
+	seriesTitles := make(map[int]string)
+	seasons := make(map[completenessSeasonKey]*SeasonCompleteness)
+	episodeNumbers := make(map[completenessSeasonKey]map[int]bool)
+	for i := range recordings {
+		recording := &recordings[i].Recording
+		episode := recording.RecordedEpisode.JSONForClient
+		if episode.Type != "recEpisode" || episode.Relationships.RecSeries == 0 {
+			continue
+		}
+		seriesID := episode.Relationships.RecSeries
+		if series := recording.RecordedSeries.JSONForClient; series.ObjectID == seriesID && len(series.Title) > 0 {
+			seriesTitles[seriesID] = series.Title
+		} else if _, wasFound := seriesTitles[seriesID]; !wasFound {
+			seriesTitles[seriesID] = ""
+		}
+
+		seasonNumber := episode.SeasonNumber
+		bundledSeason := recording.RecordedSeason.JSONForClient
+		if seasonNumber == 0 && bundledSeason.ObjectID == episode.Relationships.RecSeason {
+			seasonNumber = bundledSeason.SeasonNumber
+		}
+		key := completenessSeasonKey{seriesID, seasonNumber}
+		season, wasFound := seasons[key]
+		if !wasFound {
+			season = &SeasonCompleteness{SeasonNumber: seasonNumber}
+			seasons[key] = season
+			episodeNumbers[key] = make(map[int]bool)
+		}
+		if season.SeasonID == 0 {
+			season.SeasonID = episode.Relationships.RecSeason
+		}
+		if episode.EpisodeNumber <= 0 {
+			season.Unnumbered = append(season.Unnumbered, episode.ObjectID)
+			continue
+		}
+		episodeNumbers[key][episode.EpisodeNumber] = true
+	}
+
+	seriesByID := make(map[int]*SeriesCompleteness)
+	for key, season := range seasons {
+		for episodeNumber := range episodeNumbers[key] {
+			season.Present = append(season.Present, episodeNumber)
+		}
+		sort.Ints(season.Present)
+		sort.Ints(season.Unnumbered)
+		if len(season.Present) > 0 {
+			season.Highest = season.Present[len(season.Present)-1]
+		}
+		if !season.Specials() {
+			for episodeNumber := 1; episodeNumber < season.Highest; episodeNumber++ {
+				if !episodeNumbers[key][episodeNumber] {
+					season.Missing = append(season.Missing, episodeNumber)
+				}
+			}
+		}
+
+		series, wasFound := seriesByID[key.seriesID]
+		if !wasFound {
+			series = &SeriesCompleteness{SeriesID: key.seriesID, Title: seriesTitles[key.seriesID]}
+			seriesByID[key.seriesID] = series
+		}
+		series.Seasons = append(series.Seasons, *season)
+	}
+
+	var report CompletenessReport
+	for _, series := range seriesByID {
+		sort.Slice(series.Seasons, func(i, j int) bool {
+			return series.Seasons[i].SeasonNumber < series.Seasons[j].SeasonNumber
+		})
+		report.Series = append(report.Series, *series)
+	}
+	sort.Slice(report.Series, func(i, j int) bool {
+		if report.Series[i].Title != report.Series[j].Title {
+			return report.Series[i].Title < report.Series[j].Title
+		}
+		return report.Series[i].SeriesID < report.Series[j].SeriesID
+	})
+	return report
+}
@@ -0,0 +1,56 @@
+package tablometadata_test
+
+import (
+	"fmt"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestAnalyzeCompleteness(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z", numbered(1, 1)),
+		episodeAiredOn(t, 2, "2017-09-19T05:00Z", numbered(1, 2)),
+		episodeAiredOn(t, 3, "2017-09-19T05:00Z", numbered(1, 5)),
+		episodeAiredOn(t, 4, "2017-09-19T05:00Z", numbered(1, 5)),
+		episodeAiredOn(t, 5, "2017-09-19T05:00Z", numbered(1, 0)),
+		episodeAiredOn(t, 6, "2017-09-19T05:00Z", numbered(0, 7)),
+		episodeAiredOn(t, 7, "2017-09-19T05:00Z", numbered(2, 1)),
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+	}
+	report := tablometadata.AnalyzeCompleteness(recordings)
+
+	if len(report.Series) != 1 || report.Series[0].Title != "Midnight, Texas" || len(report.Series[0].Seasons) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	series := report.Series[0]
+
+	seasonOne, _ := series.Season(1)
+	if fmt.Sprint(seasonOne.Present) != "[1 2 5]" || fmt.Sprint(seasonOne.Missing) != "[3 4]" || seasonOne.Highest != 5 || seasonOne.Complete() {
+		t.Errorf("unexpected season 1 %+v", seasonOne)
+	}
+	if len(seasonOne.Unnumbered) != 1 || seasonOne.Unnumbered[0] != 5 || seasonOne.SeasonID != 400001 {
+		t.Errorf("expected the unnumbered episode to be set aside, got %+v", seasonOne)
+	}
+
+	specials, _ := series.Season(0)
+	if !specials.Specials() || len(specials.Missing) != 0 || fmt.Sprint(specials.Present) != "[7]" || specials.Complete() {
+		t.Errorf("unexpected specials %+v", specials)
+	}
+	if seasonTwo, _ := series.Season(2); !seasonTwo.Complete() || seasonTwo.String() != "season 2: 1 of 1 episodes, missing []" {
+		t.Errorf("unexpected season 2 %+v", seasonTwo)
+	}
+	if _, wasFound := series.Season(3); wasFound {
+		t.Error("expected no season 3")
+	}
+}
+
+func TestAnalyzeCompletenessUsesBundledSeason(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{episodeAiredOn(t, 1, "2017-09-19T05:00Z", unnumbered, func(recording *tablometadata.Recording) {
+		recording.RecordedEpisode.JSONForClient.EpisodeNumber = 4
+	})}
+	series := tablometadata.AnalyzeCompleteness(recordings).Series[0]
+	if season, wasFound := series.Season(1); !wasFound || fmt.Sprint(season.Missing) != "[1 2 3]" {
+		t.Errorf("expected the season number from recSeason, got %+v", series.Seasons)
+	}
+}
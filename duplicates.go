@@ -0,0 +1,298 @@
+package tablometadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type DuplicateReason string
+
+const (
+	DuplicateMovie         DuplicateReason = "same-movie"
+	DuplicateEpisodeNumber DuplicateReason = "same-episode-number"
+	DuplicateSimilarTitle  DuplicateReason = "similar-title"
+)
+
+// DESCRIPTIONSIMILARITY is the share of description words two recordings with
+// the same title must have in common to count as the same program.
+const DESCRIPTIONSIMILARITY = 0.6
+
+// DuplicateCopy is one recording in a duplicate group. Completeness is the
+// recorded duration as a share of the scheduled window, capped at 1.
+type DuplicateCopy struct {
+	Path         string
+	ObjectID     int
+	Resolution   ResolutionClass
+	State        RecordingState
+	Completeness float64
+	Bytes        uint64
+}
+
+// DuplicateGroup is a set of recordings of the same episode, movie or program.
+// Preferred is the copy to keep; Others can be deleted.
+type DuplicateGroup struct {
+	Reason    DuplicateReason
+	Key       string
+	Title     string
+	Preferred DuplicateCopy
+	Others    []DuplicateCopy
+}
+
+// ReclaimableBytes returns the space freed by deleting every copy but the
+// preferred one.
+func (dg DuplicateGroup) ReclaimableBytes() uint64 {
+	var total uint64
+	for _, other := range dg.Others {
+		total += other.Bytes
+	}
+	return total
+}
+
+func (dg DuplicateGroup) String() string {
+	return fmt.Sprintf("%s %s %q: keep %s, %d other copies", dg.Reason, dg.Key, dg.Title, dg.Preferred.Path, len(dg.Others))
+}
+
+// DuplicateReport lists every duplicate group, ordered by key.
+type DuplicateReport struct {
+	Groups []DuplicateGroup
+}
+
+func (dr DuplicateReport) ReclaimableBytes() uint64 {
+	var total uint64
+	for _, group := range dr.Groups {
+		total += group.ReclaimableBytes()
+	}
+	return total
+}
+
+// FindDuplicates runs the package level FindDuplicates over every recording in
+// the library.
+func (tl *Library) FindDuplicates() DuplicateReport {
+	return FindDuplicates(tl.Recordings)
+}
+
+type duplicateCandidate struct {
+	duplicate   DuplicateCopy
+	title       string
+	description []string
+	episodeKey  string
+}
+
+// FindDuplicates groups recordings of the same movie, recordings of the same
+// series, season and episode number, and episodes or programs whose titles
+// match and whose descriptions are similar. An episode without numbers whose
+// title and description match a numbered episode joins that episode's group.
+// In each group the preferred copy is the one that finished, recorded at least
+// DEFAULTMINIMUMRECORDEDSHARE of its window, has the highest resolution and is
+// the most complete, in that order.
+func FindDuplicates(recordings []LibraryRecording) DuplicateReport {
+	keyed := make(map[string][]duplicateCandidate)
+	reasons := make(map[string]DuplicateReason)
+	similar := make(map[string][]duplicateCandidate)
+	for i := range recordings {
+		recording := &recordings[i].Recording
+		primary := recording.Primary()
+		if primary == nil {
+			continue
+		}
+		client := clientOf(primary)
+		current := duplicateCandidate{duplicate: newDuplicateCopy(recordings[i].Path, primary.ObjectID(), client), title: primary.DisplayTitle()}
+
+		switch {
+		case client.Type == "recMovieAiring" && client.Relationships.RecMovie != 0:
+			key := fmt.Sprintf("movie:%d", client.Relationships.RecMovie)
+			if movie := recording.RecordedMovie.JSONForClient; len(movie.Title) > 0 {
+				current.title = movie.Title
+			}
+			keyed[key] = append(keyed[key], current)
+			reasons[key] = DuplicateMovie
+		case client.Type == "recEpisode" || client.Type == "recProgram":
+			if client.Type == "recEpisode" && client.SeasonNumber > 0 && client.EpisodeNumber > 0 {
+				current.episodeKey = fmt.Sprintf("episode:%d:S%02dE%02d", client.Relationships.RecSeries, client.SeasonNumber, client.EpisodeNumber)
+				keyed[current.episodeKey] = append(keyed[current.episodeKey], current)
+				reasons[current.episodeKey] = DuplicateEpisodeNumber
+			}
+			title := normalizeWords(client.Title)
+			if len(title) < 1 {
+				continue
+			}
+			key := fmt.Sprintf("%s:%d:%s", client.Type, client.Relationships.RecSeries, strings.Join(title, " "))
+			current.description = normalizeWords(client.Description)
+			similar[key] = append(similar[key], current)
+		}
+	}
+
+	var report DuplicateReport
+	addGroup := func(reason DuplicateReason, key string, candidates []duplicateCandidate) {
+		if len(candidates) < 2 {
+			return
+		}
+		copies := make([]DuplicateCopy, len(candidates))
+		for i, current := range candidates {
+			copies[i] = current.duplicate
+		}
+		sort.SliceStable(copies, func(i, j int) bool {
+			return preferCopy(copies[i], copies[j])
+		})
+		report.Groups = append(report.Groups, DuplicateGroup{Reason: reason, Key: key, Title: candidates[0].title, Preferred: copies[0], Others: copies[1:]})
+	}
+	for key, candidates := range similar {
+		var clusters [][]duplicateCandidate
+		for _, cluster := range clusterSimilar(candidates) {
+			episodeKey := ""
+			var unnumbered []duplicateCandidate
+			for _, current := range cluster {
+				if len(current.episodeKey) > 0 {
+					episodeKey = current.episodeKey
+				} else {
+					unnumbered = append(unnumbered, current)
+				}
+			}
+			if len(episodeKey) > 0 {
+				keyed[episodeKey] = append(keyed[episodeKey], unnumbered...)
+				continue
+			}
+			clusters = append(clusters, cluster)
+		}
+		for i, cluster := range clusters {
+			clusterKey := key
+			if len(clusters) > 1 {
+				clusterKey = fmt.Sprintf("%s#%d", key, i+1)
+			}
+			addGroup(DuplicateSimilarTitle, clusterKey, cluster)
+		}
+	}
+	for key, candidates := range keyed {
+		addGroup(reasons[key], key, candidates)
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+	return report
+}
+
+// clusterSimilar joins candidates whose descriptions are at least
+// DESCRIPTIONSIMILARITY alike, transitively, so the clusters do not depend on
+// the order of candidates. A cluster never holds two different numbered
+// episodes: those are told apart by their numbers, not their descriptions.
+func clusterSimilar(candidates []duplicateCandidate) [][]duplicateCandidate {
+	parents := make([]int, len(candidates))
+	episodeKeys := make([]string, len(candidates))
+	for i := range candidates {
+		parents[i] = i
+		episodeKeys[i] = candidates[i].episodeKey
+	}
+	find := func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			rootI, rootJ := find(i), find(j)
+			if rootI == rootJ || wordSimilarity(candidates[i].description, candidates[j].description) < DESCRIPTIONSIMILARITY {
+				continue
+			}
+			if len(episodeKeys[rootI]) > 0 && len(episodeKeys[rootJ]) > 0 && episodeKeys[rootI] != episodeKeys[rootJ] {
+				continue
+			}
+			parents[rootJ] = rootI
+			if len(episodeKeys[rootI]) < 1 {
+				episodeKeys[rootI] = episodeKeys[rootJ]
+			}
+		}
+	}
+
+	clusterIndexes := make(map[int]int)
+	var clusters [][]duplicateCandidate
+	for i := range candidates {
+		root := find(i)
+		index, wasFound := clusterIndexes[root]
+		if !wasFound {
+			index = len(clusters)
+			clusterIndexes[root] = index
+			clusters = append(clusters, nil)
+		}
+		clusters[index] = append(clusters[index], candidates[i])
+	}
+	return clusters
+}
+
+func newDuplicateCopy(path string, objectID int, client ClientJSON) DuplicateCopy {
+	duplicate := DuplicateCopy{Path: path, ObjectID: objectID, Resolution: client.Video.Resolution(), State: client.Video.State, Bytes: client.Video.Size}
+	if window, wasFound := client.RecordingWindow(time.UTC); wasFound && window.Duration() > 0 {
+		duplicate.Completeness = float64(secondsDuration(client.Video.Duration)) / float64(window.Duration())
+		if duplicate.Completeness > 1 {
+			duplicate.Completeness = 1
+		}
+	}
+	return duplicate
+}
+
+// preferCopy reports whether a is a better copy to keep than b.
+func preferCopy(a DuplicateCopy, b DuplicateCopy) bool {
+	if aFinished, bFinished := a.State == StateFinished, b.State == StateFinished; aFinished != bFinished {
+		return aFinished
+	}
+	if aComplete, bComplete := a.Completeness >= DEFAULTMINIMUMRECORDEDSHARE, b.Completeness >= DEFAULTMINIMUMRECORDEDSHARE; aComplete != bComplete {
+		return aComplete
+	}
+	if aRank, bRank := resolutionRank(a.Resolution), resolutionRank(b.Resolution); aRank != bRank {
+		return aRank > bRank
+	}
+	if a.Completeness != b.Completeness {
+		return a.Completeness > b.Completeness
+	}
+	if a.Bytes != b.Bytes {
+		return a.Bytes > b.Bytes
+	}
+	return a.Path < b.Path
+}
+
+func resolutionRank(resolution ResolutionClass) int {
+	switch resolution {
+	case Resolution1080i:
+		return 3
+	case Resolution720p:
+		return 2
+	case ResolutionSD:
+		return 1
+	}
+	return 0
+}
+
+// normalizeWords lowercases text and splits it into words, dropping
+// punctuation.
+func normalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordSimilarity returns the Jaccard similarity of two word lists. Two empty
+// lists are identical.
+func wordSimilarity(a []string, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	words := make(map[string]int)
+	for _, word := range a {
+		words[word] |= 1
+	}
+	for _, word := range b {
+		words[word] |= 2
+	}
+	shared := 0
+	for _, presence := range words {
+		if presence == 3 {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(words))
+}
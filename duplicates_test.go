@@ -0,0 +1,124 @@
+package tablometadata_test
+
+import (
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func episodeNumber(number int) func(recording *tablometadata.Recording) {
+	return func(recording *tablometadata.Recording) {
+		recording.RecordedEpisode.JSONForClient.EpisodeNumber = number
+	}
+}
+
+func describedAs(description string) func(recording *tablometadata.Recording) {
+	return func(recording *tablometadata.Recording) {
+		recording.RecordedEpisode.JSONForClient.Description = description
+	}
+}
+
+func failed(recording *tablometadata.Recording) {
+	recording.RecordedEpisode.JSONForClient.Video.State = tablometadata.StateFailed
+}
+
+func TestFindDuplicatesByEpisodeNumber(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z", func(recording *tablometadata.Recording) {
+			recording.RecordedEpisode.JSONForClient.Video.Width = 1280
+			recording.RecordedEpisode.JSONForClient.Video.Height = 720
+		}),
+		episodeAiredOn(t, 2, "2018-02-01T05:00Z"),
+		episodeAiredOn(t, 3, "2018-03-01T05:00Z", func(recording *tablometadata.Recording) {
+			recording.RecordedEpisode.JSONForClient.Video.Duration = 600
+		}),
+		episodeAiredOn(t, 4, "2018-03-08T05:00Z", episodeNumber(11)),
+	}
+	report := tablometadata.FindDuplicates(recordings)
+
+	if len(report.Groups) != 1 {
+		t.Fatalf("expected one group, got %v", report.Groups)
+	}
+	group := report.Groups[0]
+	if group.Reason != tablometadata.DuplicateEpisodeNumber || group.Key != "episode:301534:S01E10" || group.Title != "The Virgin Sacrifice" {
+		t.Errorf("unexpected group %v", group)
+	}
+	if group.Preferred.ObjectID != 2 || len(group.Others) != 2 || group.Others[0].ObjectID != 1 || group.Others[1].ObjectID != 3 {
+		t.Errorf("expected the complete 1080i copy first, got %+v", group)
+	}
+	if report.ReclaimableBytes() != 2*5302616064 {
+		t.Errorf("unexpected reclaimable bytes %d", report.ReclaimableBytes())
+	}
+}
+
+func TestFindDuplicatesBySimilarTitle(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z", unnumbered, failed),
+		episodeAiredOn(t, 2, "2018-02-01T05:00Z", unnumbered, func(recording *tablometadata.Recording) {
+			recording.RecordedEpisode.JSONForClient.Title = "the virgin sacrifice!"
+		}),
+		episodeAiredOn(t, 3, "2018-03-01T05:00Z", unnumbered, describedAs("Fiji opens a shop.")),
+	}
+	report := tablometadata.FindDuplicates(recordings)
+
+	if len(report.Groups) != 1 || report.Groups[0].Reason != tablometadata.DuplicateSimilarTitle {
+		t.Fatalf("expected one similar-title group, got %v", report.Groups)
+	}
+	if group := report.Groups[0]; group.Preferred.ObjectID != 2 || len(group.Others) != 1 || group.Others[0].State != tablometadata.StateFailed {
+		t.Errorf("expected the finished copy to be preferred, got %+v", group)
+	}
+}
+
+func TestFindDuplicatesJoinsUnnumberedToNumbered(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z"),
+		episodeAiredOn(t, 2, "2018-02-01T05:00Z", unnumbered, failed),
+		episodeAiredOn(t, 3, "2018-03-01T05:00Z", episodeNumber(11)),
+	}
+	report := tablometadata.FindDuplicates(recordings)
+
+	if len(report.Groups) != 1 {
+		t.Fatalf("expected one group, got %v", report.Groups)
+	}
+	group := report.Groups[0]
+	if group.Key != "episode:301534:S01E10" || group.Preferred.ObjectID != 1 || len(group.Others) != 1 || group.Others[0].ObjectID != 2 {
+		t.Errorf("expected the unnumbered copy to join S01E10, got %+v", group)
+	}
+}
+
+func TestFindDuplicatesClustersTransitively(t *testing.T) {
+	descriptions := []string{"one two three four five", "one two three four five six", "two three four five six seven"}
+	episodes := make([]tablometadata.LibraryRecording, len(descriptions))
+	for i, description := range descriptions {
+		episodes[i] = episodeAiredOn(t, i+1, "2017-09-19T05:00Z", unnumbered, describedAs(description))
+	}
+
+	// The first and last descriptions are only similar through the middle one.
+	for _, order := range [][]int{{0, 1, 2}, {0, 2, 1}, {2, 0, 1}} {
+		var recordings []tablometadata.LibraryRecording
+		for _, index := range order {
+			recordings = append(recordings, episodes[index])
+		}
+		report := tablometadata.FindDuplicates(recordings)
+		if len(report.Groups) != 1 || len(report.Groups[0].Others) != 2 {
+			t.Errorf("order %v: expected one group of three, got %v", order, report.Groups)
+		}
+	}
+}
+
+func TestFindDuplicatesByMovie(t *testing.T) {
+	second := decodeLibraryRecording(t, "second", sampleMovieJSON)
+	second.Recording.Airing.JSONForClient.ObjectID = 117670
+	recordings := []tablometadata.LibraryRecording{
+		decodeLibraryRecording(t, "first", sampleMovieJSON),
+		second,
+		decodeLibraryRecording(t, "episode", sampleEpisodeJSON),
+	}
+	report := tablometadata.FindDuplicates(recordings)
+	if len(report.Groups) != 1 || report.Groups[0].Key != "movie:117666" || report.Groups[0].Title != "Buying the Cow" {
+		t.Fatalf("unexpected groups %v", report.Groups)
+	}
+	if group := report.Groups[0]; group.Preferred.Path != "first" || group.Others[0].Path != "second" {
+		t.Errorf("expected identical copies to be ordered by path, got %+v", group)
+	}
+}
@@ -0,0 +1,174 @@
+package tablometadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// objectEncoder writes a JSON object one member at a time so the key order
+// matches what the Tablo writes. Key names come from the json tags of
+// structure, and the first error encountered is kept and returned by Bytes.
+type objectEncoder struct {
+	structure interface{}
+	buffer    bytes.Buffer
+	members   int
+	err       error
+}
+
+func newObjectEncoder(structure interface{}) *objectEncoder {
+	oe := &objectEncoder{structure: structure}
+	oe.buffer.WriteByte('{')
+	return oe
+}
+
+func (oe *objectEncoder) key(fieldName string) bool {
+	if oe.err != nil {
+		return false
+	}
+	jsonFieldName, err := getJSONFieldNameByName(oe.structure, fieldName)
+	if err != nil {
+		oe.err = err
+		return false
+	}
+	oe.rawKey(jsonFieldName)
+	return oe.err == nil
+}
+
+func (oe *objectEncoder) rawKey(jsonFieldName string) {
+	if oe.members > 0 {
+		oe.buffer.WriteByte(',')
+	}
+	oe.members++
+	oe.err = writeJSONString(&oe.buffer, jsonFieldName)
+	oe.buffer.WriteByte(':')
+}
+
+func (oe *objectEncoder) String(fieldName string, value string) {
+	if oe.key(fieldName) {
+		oe.err = writeJSONString(&oe.buffer, value)
+	}
+}
+
+func (oe *objectEncoder) Int(fieldName string, value int) {
+	if oe.key(fieldName) {
+		oe.buffer.WriteString(strconv.Itoa(value))
+	}
+}
+
+func (oe *objectEncoder) Uint(fieldName string, value uint64) {
+	if oe.key(fieldName) {
+		oe.buffer.WriteString(strconv.FormatUint(value, 10))
+	}
+}
+
+func (oe *objectEncoder) Bool(fieldName string, value bool) {
+	if oe.key(fieldName) {
+		oe.buffer.WriteString(strconv.FormatBool(value))
+	}
+}
+
+// Float writes value with a fixed number of decimals, which is how the Tablo
+// distinguishes 7200.0 from 3600 and 0.250 from 0.25.
+func (oe *objectEncoder) Float(fieldName string, value float32, decimals int) {
+	if err := checkFloat(fieldName, float64(value)); err != nil {
+		if oe.err == nil {
+			oe.err = err
+		}
+		return
+	}
+	if oe.key(fieldName) {
+		oe.buffer.WriteString(strconv.FormatFloat(float64(value), 'f', decimals, 32))
+	}
+}
+
+func (oe *objectEncoder) Value(fieldName string, value interface{}) {
+	if !oe.key(fieldName) {
+		return
+	}
+	jsonData, err := marshalWithoutHTMLEscape(value)
+	if err != nil {
+		oe.err = err
+		return
+	}
+	oe.buffer.Write(jsonData)
+}
+
+// TypeName writes the "type" member for encoders of types that have no Type
+// field, such as the typed clients.
+func (oe *objectEncoder) TypeName(tabloType string) {
+	if oe.err != nil {
+		return
+	}
+	oe.rawKey("type")
+	if oe.err == nil {
+		oe.err = writeJSONString(&oe.buffer, tabloType)
+	}
+}
+
+// RawMember writes a member whose key did not come from a struct field, such as
+// one preserved from a decoded objectLayout.
+func (oe *objectEncoder) RawMember(jsonFieldName string, jsonData []byte) {
+	if oe.err != nil {
+		return
+	}
+	oe.rawKey(jsonFieldName)
+	if oe.err == nil {
+		oe.buffer.Write(jsonData)
+	}
+}
+
+// Populated writes every exported field of the structure that is not its zero
+// value, in declaration order. It is the layout for objects whose Tablo key
+// order is not known.
+func (oe *objectEncoder) Populated() {
+	structureValue := reflect.ValueOf(oe.structure)
+	structureType := structureValue.Type()
+	for i := 0; i < structureType.NumField(); i++ {
+		field := structureType.Field(i)
+		if len(field.PkgPath) > 0 || structureValue.Field(i).IsZero() {
+			continue
+		}
+		oe.Value(field.Name, structureValue.Field(i).Interface())
+	}
+}
+
+func (oe *objectEncoder) Bytes() ([]byte, error) {
+	if oe.err != nil {
+		return nil, oe.err
+	}
+	oe.buffer.WriteByte('}')
+	return oe.buffer.Bytes(), nil
+}
+
+// checkFloat rejects the float values JSON cannot represent.
+func checkFloat(fieldName string, value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("%s: unsupported float value %v", fieldName, value)
+	}
+	return nil
+}
+
+func writeJSONString(buffer *bytes.Buffer, value string) error {
+	jsonData, err := marshalWithoutHTMLEscape(value)
+	if err != nil {
+		return err
+	}
+	buffer.Write(jsonData)
+	return nil
+}
+
+// marshalWithoutHTMLEscape is json.Marshal without escaping &, < and >, which
+// the Tablo writes verbatim in titles and descriptions.
+func marshalWithoutHTMLEscape(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,113 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+var hostileStrings = []string{
+	`The "Best" Night`,
+	`C:\Recordings\new`,
+	"line one\nline two\ttabbed",
+	"bell\a and nul\x00",
+	`</script><b>&amp;</b>`,
+	"François \u2028 separator",
+}
+
+func TestClientJSONEscapesEveryString(t *testing.T) {
+	for _, hostile := range hostileStrings {
+		hostileDate := civilDateFromText(t, hostile)
+		clients := []tablometadata.ClientJSON{
+			{Type: "recMovie", Title: hostile, Plot: hostile, MPAARating: hostile, Cast: []string{hostile}, Directors: []string{hostile}, Relationships: tablometadata.Relationships{Genres: []int{1}}},
+			{Type: "recEpisode", Title: hostile, Description: hostile, OriginalAirDate: hostileDate, Qualifiers: []string{hostile}, Relationships: tablometadata.Relationships{RecSeason: 2, RecSeries: 1}, Video: tablometadata.VideoInfo{State: tablometadata.RecordingState(hostile)}, User: tablometadata.UserInfo{UserType: hostile}},
+			{Type: "recSeries", Title: hostile, Description: hostile, OriginalAirDate: hostileDate, Cast: []string{hostile}, Relationships: tablometadata.Relationships{RecSeries: 1}},
+			{Type: "recMovieAiring", Relationships: tablometadata.Relationships{RecMovie: 1}, Video: tablometadata.VideoInfo{State: tablometadata.RecordingState(hostile)}, User: tablometadata.UserInfo{UserType: hostile}},
+		}
+		for _, client := range clients {
+			jsonData, err := client.MarshalJSON()
+			if err != nil {
+				t.Fatalf("%s %q: %v", client.Type, hostile, err)
+			}
+			if !json.Valid(jsonData) {
+				t.Fatalf("%s %q produced invalid JSON: %s", client.Type, hostile, jsonData)
+			}
+
+			var decoded tablometadata.ClientJSON
+			if err := json.Unmarshal(jsonData, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Title != client.Title || decoded.Plot != client.Plot || decoded.Description != client.Description ||
+				decoded.MPAARating != client.MPAARating || decoded.OriginalAirDate.String() != client.OriginalAirDate.String() ||
+				decoded.Video.State != client.Video.State || decoded.User.UserType != client.User.UserType {
+				t.Errorf("%s %q did not survive a round trip: %s", client.Type, hostile, jsonData)
+			}
+		}
+	}
+}
+
+// civilDateFromText decodes text, which need not be a date, the way it would be
+// read from a meta file.
+func civilDateFromText(t *testing.T, text string) tablometadata.CivilDate {
+	jsonData, err := json.Marshal(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var civilDate tablometadata.CivilDate
+	if err := json.Unmarshal(jsonData, &civilDate); err != nil {
+		t.Fatal(err)
+	}
+	return civilDate
+}
+
+func TestClientJSONKeepsTabloKeyOrder(t *testing.T) {
+	season := tablometadata.ClientJSON{Type: "recSeason", SeasonNumber: 2, ObjectID: 9, Relationships: tablometadata.Relationships{RecSeries: 8}}
+	jsonData, err := season.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(jsonData) != `{"seasonNumber":2,"relationships":{"recSeries":8},"objectID":9,"type":"recSeason"}` {
+		t.Errorf("unexpected season encoding %s", jsonData)
+	}
+}
+
+func TestClientJSONEncodesUnknownTypes(t *testing.T) {
+	var cases = []struct {
+		client   tablometadata.ClientJSON
+		expected string
+	}{
+		{tablometadata.ClientJSON{}, `{}`},
+		{tablometadata.ClientJSON{Type: "recChannel", Title: "KTCA", ObjectID: 5}, `{"title":"KTCA","type":"recChannel","objectID":5}`},
+	}
+	for _, testCase := range cases {
+		jsonData, err := testCase.client.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(jsonData) != testCase.expected {
+			t.Errorf("expected %s, got %s", testCase.expected, jsonData)
+		}
+	}
+
+	recording := tablometadata.Recording{RecordedEpisode: tablometadata.RecEpisode{JSONForClient: tablometadata.ClientJSON{Type: "recEpisodeV2", ObjectID: 7}}}
+	jsonData, err := json.Marshal(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(jsonData) || !strings.Contains(string(jsonData), `"type":"recEpisodeV2"`) {
+		t.Errorf("unexpected recording encoding %s", jsonData)
+	}
+}
+
+func TestClientJSONDoesNotEscapeHTML(t *testing.T) {
+	series := tablometadata.ClientJSON{Type: "recSeries", Title: "Law & Order", Relationships: tablometadata.Relationships{RecSeries: 1}}
+	jsonData, err := series.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(jsonData), `"title":"Law & Order"`) {
+		t.Errorf("expected the ampersand to be written verbatim: %s", jsonData)
+	}
+}
@@ -0,0 +1,109 @@
+package tablometadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownDatePattern = errors.New("unknown date pattern")
+	ErrFieldNotFound      = errors.New("field not found")
+	ErrJSONTagNotFound    = errors.New("json tag not found")
+)
+
+// ParseError locates a value that could not be decoded. Path is the JSON path
+// from the top of the document, e.g. recEpisode.jsonForClient.airDate, and
+// Offset is the byte offset of Value within Source. Use errors.As to get one
+// from the error returned by json.Unmarshal or LoadLibrary.
+type ParseError struct {
+	Source string
+	Offset int64
+	Path   string
+	Value  string
+	Err    error
+}
+
+func (pe *ParseError) Error() string {
+	var message strings.Builder
+	if len(pe.Source) > 0 {
+		message.WriteString(pe.Source)
+		message.WriteString(": ")
+	}
+	if len(pe.Path) > 0 {
+		message.WriteString(pe.Path)
+		message.WriteString(": ")
+	}
+	message.WriteString(pe.Err.Error())
+	if len(pe.Value) > 0 {
+		fmt.Fprintf(&message, " (value %s at offset %d)", pe.Value, pe.Offset)
+	} else {
+		fmt.Fprintf(&message, " (offset %d)", pe.Offset)
+	}
+	return message.String()
+}
+
+func (pe *ParseError) Unwrap() error {
+	return pe.Err
+}
+
+// FieldLookupError is returned when an encoder asks for the json tag of a
+// struct field that does not exist or has no tag.
+type FieldLookupError struct {
+	TypeName  string
+	FieldName string
+	Err       error
+}
+
+func (fe *FieldLookupError) Error() string {
+	return fmt.Sprintf("%s.%s: %v", fe.TypeName, fe.FieldName, fe.Err)
+}
+
+func (fe *FieldLookupError) Unwrap() error {
+	return fe.Err
+}
+
+// wrapMemberError places err, returned while decoding the member key whose
+// value starts at offset, into a ParseError rooted at the enclosing object.
+func wrapMemberError(err error, key string, offset int64, raw []byte) *ParseError {
+	var parseError *ParseError
+	if errors.As(err, &parseError) {
+		return &ParseError{Source: parseError.Source, Offset: offset + parseError.Offset, Path: joinJSONPath(key, parseError.Path),
+			Value: parseError.Value, Err: parseError.Err}
+	}
+
+	path := key
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && len(typeError.Field) > 0 {
+		path = joinJSONPath(key, typeError.Field)
+	}
+	return &ParseError{Offset: offset, Path: path, Value: string(raw), Err: err}
+}
+
+// newParseError turns an error from reading the document at source into a
+// ParseError, keeping the location of syntax errors.
+func newParseError(source string, err error) error {
+	var parseError *ParseError
+	if errors.As(err, &parseError) {
+		located := *parseError
+		located.Source = source
+		return &located
+	}
+	located := &ParseError{Source: source, Err: err}
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		located.Offset = syntaxError.Offset
+	}
+	return located
+}
+
+func joinJSONPath(prefix string, path string) string {
+	if len(path) < 1 {
+		return prefix
+	}
+	if strings.HasPrefix(path, "[") {
+		return prefix + path
+	}
+	return prefix + "." + path
+}
@@ -0,0 +1,86 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestParseErrorLocatesBadDate(t *testing.T) {
+	data := strings.Replace(sampleEpisodeJSON, `"airDate":"2017-09-19T05:00Z"`, `"airDate":"Tuesday night"`, 1)
+	var recording tablometadata.Recording
+	err := json.Unmarshal([]byte(data), &recording)
+
+	var parseError *tablometadata.ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+	if parseError.Path != "recEpisode.jsonForClient.airDate" || parseError.Value != `"Tuesday night"` {
+		t.Errorf("unexpected location %+v", parseError)
+	}
+	if !errors.Is(err, tablometadata.ErrUnknownDatePattern) {
+		t.Errorf("expected ErrUnknownDatePattern, got %v", parseError.Err)
+	}
+	if located := data[parseError.Offset : parseError.Offset+int64(len(parseError.Value))]; located != parseError.Value {
+		t.Errorf("offset %d points at %q", parseError.Offset, located)
+	}
+}
+
+func TestParseErrorLocatesArrayElement(t *testing.T) {
+	data := strings.Replace(sampleMovieJSON, `"imageID":114189`, `"imageID":"114189"`, 1)
+	var recording tablometadata.Recording
+	err := json.Unmarshal([]byte(data), &recording)
+
+	var parseError *tablometadata.ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+	if parseError.Path != "recMovie.imageJson.images[0].imageID" || parseError.Value != `"114189"` {
+		t.Errorf("unexpected location %+v", parseError)
+	}
+	if located := data[parseError.Offset : parseError.Offset+int64(len(parseError.Value))]; located != parseError.Value {
+		t.Errorf("offset %d points at %q", parseError.Offset, located)
+	}
+	var typeError *json.UnmarshalTypeError
+	if !errors.As(err, &typeError) {
+		t.Errorf("expected the json.UnmarshalTypeError to be wrapped, got %v", parseError.Err)
+	}
+}
+
+func TestParseErrorCarriesSourcePath(t *testing.T) {
+	root := t.TempDir()
+	path := writeMetaFile(t, root, 343176, strings.Replace(sampleEpisodeJSON, `"airDate":"2017-09-19T05:00Z"`, `"airDate":"soon"`, 1))
+	syntaxPath := writeMetaFile(t, root, 343177, `{"recEpisode":{]`)
+
+	library, err := tablometadata.LoadLibrary(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(library.Errors) != 2 {
+		t.Fatalf("expected two load errors, got %v", library.Errors)
+	}
+	for _, loadError := range library.Errors {
+		var parseError *tablometadata.ParseError
+		if !errors.As(loadError, &parseError) || parseError.Source != loadError.Path {
+			t.Errorf("expected a ParseError for %s, got %v", loadError.Path, loadError.Err)
+		}
+	}
+	if filepath.Clean(library.Errors[0].Path) != path || !strings.Contains(library.Errors[0].Error(), "recEpisode.jsonForClient.airDate") {
+		t.Errorf("unexpected error %v", library.Errors[0])
+	}
+	var parseError *tablometadata.ParseError
+	if errors.As(library.Errors[1], &parseError); library.Errors[1].Path != syntaxPath || parseError.Offset != 16 {
+		t.Errorf("expected the syntax error offset, got %+v", parseError)
+	}
+}
+
+func TestFieldLookupError(t *testing.T) {
+	err := &tablometadata.FieldLookupError{TypeName: "ClientJSON", FieldName: "Bogus", Err: tablometadata.ErrFieldNotFound}
+	if !errors.Is(err, tablometadata.ErrFieldNotFound) || err.Error() != "ClientJSON.Bogus: field not found" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
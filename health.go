@@ -0,0 +1,117 @@
+package tablometadata
+
+import (
+	"fmt"
+	"time"
+)
+
+type HealthIssueKind string
+
+const (
+	NotFinished      HealthIssueKind = "not-finished"
+	Truncated        HealthIssueKind = "truncated"
+	ZeroSize         HealthIssueKind = "zero-size"
+	ExcessivePadding HealthIssueKind = "excessive-padding"
+)
+
+const DEFAULTMINIMUMRECORDEDSHARE = 0.9
+
+// HealthOptions tunes CheckHealth. A recording counts as truncated when its
+// video is shorter than MinimumRecordedShare of the window it was scheduled to
+// capture; zero uses DEFAULTMINIMUMRECORDEDSHARE.
+type HealthOptions struct {
+	MinimumRecordedShare float64
+}
+
+// HealthIssue is one problem with a recording's video. Expected and Actual are
+// the durations the check compared, where it compared any.
+type HealthIssue struct {
+	Kind     HealthIssueKind
+	ObjectID int
+	Path     string
+	Title    string
+	Message  string
+	Expected time.Duration
+	Actual   time.Duration
+}
+
+func (hi HealthIssue) String() string {
+	return fmt.Sprintf("%s %d %q: %s (%s)", hi.Kind, hi.ObjectID, hi.Title, hi.Message, hi.Path)
+}
+
+type HealthReport struct {
+	Issues []HealthIssue
+}
+
+func (hr HealthReport) OK() bool {
+	return len(hr.Issues) == 0
+}
+
+// ByKind returns the issues of kind, in report order.
+func (hr HealthReport) ByKind(kind HealthIssueKind) []HealthIssue {
+	var issues []HealthIssue
+	for _, issue := range hr.Issues {
+		if issue.Kind == kind {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// CheckHealth runs the package level CheckHealth over every recording in the
+// library.
+func (tl *Library) CheckHealth(options HealthOptions) HealthReport {
+	return CheckHealth(tl.Recordings, options)
+}
+
+// CheckHealth reports recordings whose video state is not finished, finished
+// recordings much shorter than their scheduled window (a likely tuner or signal
+// dropout), recordings with no video data, and recordings whose padding is
+// longer than the program itself.
+func CheckHealth(recordings []LibraryRecording, options HealthOptions) HealthReport {
+	minimumShare := options.MinimumRecordedShare
+	if minimumShare <= 0 {
+		minimumShare = DEFAULTMINIMUMRECORDEDSHARE
+	}
+
+	var report HealthReport
+	for i := range recordings {
+		primary := recordings[i].Recording.Primary()
+		if primary == nil {
+			continue
+		}
+		client := clientOf(primary)
+		issue := HealthIssue{ObjectID: primary.ObjectID(), Path: recordings[i].Path, Title: primary.DisplayTitle()}
+		video := client.Video
+
+		if video.State != StateFinished {
+			report.add(issue, NotFinished, "video state is %q", video.State)
+		}
+		if video.Size == 0 {
+			report.add(issue, ZeroSize, "video size is 0 bytes")
+		}
+
+		window, wasFound := client.RecordingWindow(time.UTC)
+		if !wasFound {
+			continue
+		}
+		issue.Expected = window.Duration()
+		issue.Actual = secondsDuration(video.Duration)
+		if video.State == StateFinished && float64(issue.Actual) < minimumShare*float64(issue.Expected) {
+			report.add(issue, Truncated, "recorded %v of a %v window", issue.Actual, issue.Expected)
+		}
+
+		issue.Expected = window.Scheduled()
+		issue.Actual = window.Duration() - window.Scheduled()
+		if issue.Expected > 0 && issue.Actual > issue.Expected {
+			report.add(issue, ExcessivePadding, "%v of padding on a %v program", issue.Actual, issue.Expected)
+		}
+	}
+	return report
+}
+
+func (hr *HealthReport) add(issue HealthIssue, kind HealthIssueKind, format string, args ...interface{}) {
+	issue.Kind = kind
+	issue.Message = fmt.Sprintf(format, args...)
+	hr.Issues = append(hr.Issues, issue)
+}
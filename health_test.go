@@ -0,0 +1,61 @@
+package tablometadata_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestCheckHealthClean(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+		decodeLibraryRecording(t, "episode", sampleEpisodeJSON),
+		decodeLibraryRecording(t, "sport", sampleSportEventJSON),
+		decodeLibraryRecording(t, "manual", sampleManualProgramJSON),
+		decodeLibraryRecording(t, "program", sampleProgramJSON),
+	}
+	if report := tablometadata.CheckHealth(recordings, tablometadata.HealthOptions{}); !report.OK() {
+		t.Errorf("expected a healthy library, got %v", report.Issues)
+	}
+}
+
+func TestCheckHealthFindsProblems(t *testing.T) {
+	failed := decodeLibraryRecording(t, "failed", sampleEpisodeJSON)
+	failed.Recording.RecordedEpisode.JSONForClient.Video.State = tablometadata.StateFailed
+	failed.Recording.RecordedEpisode.JSONForClient.Video.Size = 0
+	truncated := decodeLibraryRecording(t, "truncated", sampleEpisodeJSON)
+	truncated.Recording.RecordedEpisode.JSONForClient.Video.Duration = 1200
+	padded := decodeLibraryRecording(t, "padded", sampleMovieJSON)
+	padded.Recording.Airing.JSONForClient.Video.ScheduleOffsetEnd = 7300
+	padded.Recording.Airing.JSONForClient.Video.Duration = 14515
+	recordings := []tablometadata.LibraryRecording{failed, truncated, padded}
+
+	report := tablometadata.CheckHealth(recordings, tablometadata.HealthOptions{})
+	if len(report.Issues) != 4 {
+		t.Fatalf("expected four issues, got %v", report.Issues)
+	}
+	if issues := report.ByKind(tablometadata.NotFinished); len(issues) != 1 || issues[0].Path != "failed" || !strings.Contains(issues[0].Message, `"failed"`) {
+		t.Errorf("unexpected not-finished issues %v", issues)
+	}
+	if issues := report.ByKind(tablometadata.ZeroSize); len(issues) != 1 || issues[0].Path != "failed" {
+		t.Errorf("unexpected zero-size issues %v", issues)
+	}
+	issues := report.ByKind(tablometadata.Truncated)
+	if len(issues) != 1 || issues[0].Path != "truncated" || issues[0].Title != "The Virgin Sacrifice" {
+		t.Fatalf("unexpected truncated issues %v", issues)
+	}
+	if issues[0].Expected != 5420*time.Second || issues[0].Actual != 1200*time.Second {
+		t.Errorf("unexpected durations %v and %v", issues[0].Expected, issues[0].Actual)
+	}
+	issues = report.ByKind(tablometadata.ExcessivePadding)
+	if len(issues) != 1 || issues[0].Path != "padded" || issues[0].Expected != 2*time.Hour || issues[0].Actual != 7315*time.Second {
+		t.Errorf("unexpected padding issues %v", issues)
+	}
+
+	lenient := tablometadata.CheckHealth(recordings, tablometadata.HealthOptions{MinimumRecordedShare: 0.2})
+	if len(lenient.ByKind(tablometadata.Truncated)) != 0 {
+		t.Errorf("expected a lower threshold to accept the short recording, got %v", lenient.Issues)
+	}
+}
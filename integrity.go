@@ -0,0 +1,238 @@
+package tablometadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type IntegrityIssueKind string
+
+const (
+	BrokenReference    IntegrityIssueKind = "broken-reference"
+	ConflictingObject  IntegrityIssueKind = "conflicting-object"
+	DuplicateObjectID  IntegrityIssueKind = "duplicate-object-id"
+	MissingChannelLink IntegrityIssueKind = "missing-channel-link"
+	MissingObjectID    IntegrityIssueKind = "missing-object-id"
+)
+
+// IntegrityIssue is one problem found across a set of meta files. Paths lists
+// every meta file involved.
+type IntegrityIssue struct {
+	Kind     IntegrityIssueKind
+	ObjectID int
+	Paths    []string
+	Message  string
+}
+
+func (ii IntegrityIssue) String() string {
+	return fmt.Sprintf("%s %d: %s (%s)", ii.Kind, ii.ObjectID, ii.Message, strings.Join(ii.Paths, ", "))
+}
+
+type IntegrityReport struct {
+	Issues []IntegrityIssue
+}
+
+func (ir IntegrityReport) OK() bool {
+	return len(ir.Issues) == 0
+}
+
+type objectOccurrence struct {
+	path   string
+	client ClientJSON
+}
+
+type objectReference struct {
+	name      string
+	objectID  int
+	tabloType string
+}
+
+// CheckIntegrity runs the package level CheckIntegrity over every recording in
+// the library.
+func (tl *Library) CheckIntegrity() IntegrityReport {
+	return CheckIntegrity(tl.Recordings)
+}
+
+// CheckIntegrity reports relationships that point at objects no meta file
+// contains, shared objects (series, seasons, movies, sport organizations and
+// teams) whose copies disagree, object IDs used by more than one recorded
+// object or object type, objects without an object ID and recorded objects
+// without a channel link. A channel link is broken when no meta file contains
+// that recChannel. Objects without an ID are reported one by one rather than
+// grouped, since they are not copies of each other.
+func CheckIntegrity(recordings []LibraryRecording) IntegrityReport {
+	var report IntegrityReport
+	occurrences := make(map[int][]objectOccurrence)
+	var primaries []objectOccurrence
+	for i := range recordings {
+		recording := &recordings[i].Recording
+		for _, object := range recording.Objects() {
+			occurrence := objectOccurrence{path: recordings[i].Path, client: clientOf(object)}
+			if object.ObjectID() == 0 {
+				report.add(MissingObjectID, 0, []string{occurrence.path}, "%s has no objectID", occurrence.client.Type)
+				continue
+			}
+			occurrences[object.ObjectID()] = append(occurrences[object.ObjectID()], occurrence)
+		}
+		if primary := recording.Primary(); primary != nil {
+			primaries = append(primaries, objectOccurrence{path: recordings[i].Path, client: clientOf(primary)})
+		}
+	}
+
+	objectIDs := make([]int, 0, len(occurrences))
+	for objectID := range occurrences {
+		objectIDs = append(objectIDs, objectID)
+	}
+	sort.Ints(objectIDs)
+
+	for _, objectID := range objectIDs {
+		report.checkObject(objectID, occurrences[objectID])
+	}
+	for _, primary := range primaries {
+		report.checkReferences(primary.path, primary.client, occurrences)
+	}
+	for _, objectID := range objectIDs {
+		for _, occurrence := range occurrences[objectID] {
+			if occurrence.client.Type == "recSeason" {
+				report.checkReferences(occurrence.path, occurrence.client, occurrences)
+			}
+		}
+	}
+	return report
+}
+
+func (ir *IntegrityReport) add(kind IntegrityIssueKind, objectID int, paths []string, format string, args ...interface{}) {
+	ir.Issues = append(ir.Issues, IntegrityIssue{Kind: kind, ObjectID: objectID, Paths: uniquePaths(paths), Message: fmt.Sprintf(format, args...)})
+}
+
+func (ir *IntegrityReport) checkObject(objectID int, objectOccurrences []objectOccurrence) {
+	var paths []string
+	types := make(map[string]bool)
+	for _, occurrence := range objectOccurrences {
+		paths = append(paths, occurrence.path)
+		types[occurrence.client.Type] = true
+	}
+	if len(types) > 1 {
+		ir.add(DuplicateObjectID, objectID, paths, "object ID is used by %d object types", len(types))
+		return
+	}
+
+	first := objectOccurrences[0].client
+	if isRecordedType(first.Type) {
+		if len(uniquePaths(paths)) > 1 {
+			ir.add(DuplicateObjectID, objectID, paths, "%s is recorded in more than one meta file", first.Type)
+		}
+		return
+	}
+
+	for _, field := range sharedObjectFields(first.Type) {
+		values := make(map[string][]string)
+		var order []string
+		for _, occurrence := range objectOccurrences {
+			value := field.value(occurrence.client)
+			if _, seen := values[value]; !seen {
+				order = append(order, value)
+			}
+			values[value] = append(values[value], occurrence.path)
+		}
+		if len(order) > 1 {
+			var conflictingPaths []string
+			for _, value := range order {
+				conflictingPaths = append(conflictingPaths, values[value]...)
+			}
+			ir.add(ConflictingObject, objectID, conflictingPaths, "%s has %d different %s values: %s", first.Type, len(order), field.name, quoteAll(order))
+		}
+	}
+}
+
+func (ir *IntegrityReport) checkReferences(path string, client ClientJSON, occurrences map[int][]objectOccurrence) {
+	relationships := client.Relationships
+	references := []objectReference{
+		{"recSeries", relationships.RecSeries, "recSeries"},
+		{"recSeason", relationships.RecSeason, "recSeason"},
+		{"recMovie", relationships.RecMovie, "recMovie"},
+		{"recSportOrganization", relationships.RecSportOrganization, "recSportOrganization"},
+		{"recChannel", relationships.RecChannel, "recChannel"},
+	}
+	for _, teamID := range relationships.RecSportTeams {
+		references = append(references, objectReference{"recSportTeams", teamID, "recSportTeam"})
+	}
+
+	for _, reference := range references {
+		if reference.objectID == 0 {
+			continue
+		}
+		if !hasObjectOfType(occurrences[reference.objectID], reference.tabloType) {
+			ir.add(BrokenReference, client.ObjectID, []string{path}, "%s links %s %d, which no meta file contains",
+				client.Type, reference.name, reference.objectID)
+		}
+	}
+	if isRecordedType(client.Type) && relationships.RecChannel == 0 {
+		ir.add(MissingChannelLink, client.ObjectID, []string{path}, "%s has no recChannel link", client.Type)
+	}
+}
+
+func isRecordedType(tabloType string) bool {
+	switch tabloType {
+	case "recEpisode", "recMovieAiring", "recSportEvent", "recManualProgram", "recProgram":
+		return true
+	}
+	return false
+}
+
+type sharedObjectField struct {
+	name  string
+	value func(client ClientJSON) string
+}
+
+func sharedObjectFields(tabloType string) []sharedObjectField {
+	title := sharedObjectField{"title", func(client ClientJSON) string { return client.Title }}
+	description := sharedObjectField{"description", func(client ClientJSON) string { return client.Description }}
+	switch tabloType {
+	case "recSeries":
+		return []sharedObjectField{title, description}
+	case "recSeason":
+		return []sharedObjectField{
+			{"seasonNumber", func(client ClientJSON) string { return fmt.Sprint(client.SeasonNumber) }},
+			{"relationships.recSeries", func(client ClientJSON) string { return fmt.Sprint(client.Relationships.RecSeries) }},
+		}
+	case "recMovie":
+		return []sharedObjectField{title,
+			{"plot", func(client ClientJSON) string { return client.Plot }},
+			{"releaseYear", func(client ClientJSON) string { return fmt.Sprint(client.ReleaseYear) }},
+		}
+	case "recSportOrganization", "recSportTeam", "recChannel":
+		return []sharedObjectField{title}
+	}
+	return nil
+}
+
+func hasObjectOfType(objectOccurrences []objectOccurrence, tabloType string) bool {
+	for _, occurrence := range objectOccurrences {
+		if occurrence.client.Type == tabloType {
+			return true
+		}
+	}
+	return false
+}
+
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
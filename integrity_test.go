@@ -0,0 +1,87 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func decodeLibraryRecording(t *testing.T, path string, sampleJSON string) tablometadata.LibraryRecording {
+	t.Helper()
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	return tablometadata.LibraryRecording{Path: path, ObjectID: recording.Primary().ObjectID(), Recording: recording}
+}
+
+func TestCheckIntegrityClean(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+		decodeLibraryRecording(t, "episode", sampleEpisodeJSON),
+		decodeLibraryRecording(t, "sport", sampleSportEventJSON),
+	}
+	if report := tablometadata.CheckIntegrity(recordings); !report.OK() {
+		t.Errorf("expected no issues, got %v", report.Issues)
+	}
+}
+
+func TestCheckIntegrityFindsProblems(t *testing.T) {
+	original := decodeLibraryRecording(t, "a/meta.txt", sampleEpisodeJSON)
+
+	retitled := decodeLibraryRecording(t, "b/meta.txt", sampleEpisodeJSON)
+	retitled.Recording.RecordedEpisode.JSONForClient.ObjectID = 343177
+	retitled.Recording.RecordedSeries.JSONForClient.Title = "Midnight Texas"
+	retitled.Recording.RecordedEpisode.JSONForClient.Relationships.RecChannel = 185239
+	retitled.Recording.RecordedChannel = tablometadata.RecChannel{}
+
+	duplicate := decodeLibraryRecording(t, "c/meta.txt", sampleEpisodeJSON)
+
+	orphanAiring := decodeLibraryRecording(t, "d/meta.txt", sampleMovieJSON)
+	orphanAiring.Recording.RecordedMovie = tablometadata.RecMovie{}
+	orphanAiring.Recording.Airing.JSONForClient.Relationships.RecChannel = 0
+
+	report := tablometadata.CheckIntegrity([]tablometadata.LibraryRecording{original, retitled, duplicate, orphanAiring})
+
+	expected := []struct {
+		kind     tablometadata.IntegrityIssueKind
+		objectID int
+		paths    int
+	}{
+		{tablometadata.ConflictingObject, 301534, 3},
+		{tablometadata.DuplicateObjectID, 343176, 2},
+		{tablometadata.BrokenReference, 343177, 1},
+		{tablometadata.BrokenReference, 117665, 1},
+		{tablometadata.MissingChannelLink, 117665, 1},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %v", len(expected), report.Issues)
+	}
+	for i, issue := range report.Issues {
+		if issue.Kind != expected[i].kind || issue.ObjectID != expected[i].objectID || len(issue.Paths) != expected[i].paths {
+			t.Errorf("issue %d: expected %s %d in %d files, got %s", i, expected[i].kind, expected[i].objectID, expected[i].paths, issue)
+		}
+	}
+}
+
+func TestCheckIntegrityReportsMissingObjectIDs(t *testing.T) {
+	first := decodeLibraryRecording(t, "a/meta.txt", sampleEpisodeJSON)
+	first.Recording.RecordedSeries.JSONForClient.ObjectID = 0
+	first.Recording.RecordedEpisode.JSONForClient.Relationships.RecSeries = 0
+	first.Recording.RecordedSeason.JSONForClient.Relationships.RecSeries = 0
+	second := decodeLibraryRecording(t, "b/meta.txt", sampleMovieJSON)
+	second.Recording.RecordedMovie.JSONForClient.ObjectID = 0
+	second.Recording.Airing.JSONForClient.Relationships.RecMovie = 0
+
+	report := tablometadata.CheckIntegrity([]tablometadata.LibraryRecording{first, second})
+	if len(report.Issues) != 2 {
+		t.Fatalf("expected two missing IDs, got %v", report.Issues)
+	}
+	for i, path := range []string{"a/meta.txt", "b/meta.txt"} {
+		issue := report.Issues[i]
+		if issue.Kind != tablometadata.MissingObjectID || len(issue.Paths) != 1 || issue.Paths[0] != path {
+			t.Errorf("expected a missing object ID in %s, got %s", path, issue)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package tablometadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// rawMember is one member of a decoded JSON object exactly as it was read.
+// decoded is a copy of the field the member was decoded into, taken right
+// after decoding, so MarshalJSON can tell whether the field has changed
+// without decoding Value again. It is invalid for members no field matches.
+type rawMember struct {
+	Key     string
+	Value   json.RawMessage
+	Offset  int64
+	decoded reflect.Value
+}
+
+// objectLayout remembers every member of an object decoded in lossless mode,
+// in order, including the ones the structs do not model. MarshalJSON writes it
+// back, so unchanged members come out byte for byte and unknown members are
+// not lost.
+type objectLayout struct {
+	members []rawMember
+}
+
+// layoutHolder is implemented by the pointer types that keep an objectLayout.
+type layoutHolder interface {
+	setLayout(layout *objectLayout)
+}
+
+// decodeState carries the decoding mode through nested objects. In lenient
+// mode member errors become warnings, located by JSON path, instead of
+// stopping the decode. In lossless mode every object keeps its layout.
+type decodeState struct {
+	lenient  bool
+	lossless bool
+	warnings []*ParseError
+}
+
+var layoutHolderType = reflect.TypeOf((*layoutHolder)(nil)).Elem()
+
+// valueStream reads one JSON document in a single pass. Nested objects are
+// decoded from the same stream rather than from copies of their bytes, so the
+// cost of a decode does not grow with the nesting depth.
+type valueStream struct {
+	data    []byte
+	decoder *json.Decoder
+}
+
+// valueStart returns the offset of the next value, skipping whitespace and
+// the colon or comma before it.
+func (vs *valueStream) valueStart() int64 {
+	offset := vs.decoder.InputOffset()
+	for offset < int64(len(vs.data)) {
+		switch vs.data[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// skip reads the next value whole.
+func (vs *valueStream) skip() (json.RawMessage, error) {
+	var raw json.RawMessage
+	err := vs.decoder.Decode(&raw)
+	return raw, err
+}
+
+// decodeObject decodes the JSON object in data into the struct target points
+// to. plain is target converted to a type without UnmarshalJSON, so the common
+// case is a plain json.Unmarshal; only when that fails is data decoded again
+// member by member to locate the error by JSON path. previous is target's
+// layout: after a lossless decode every object below target kept one, and an
+// ordinary decode forgets them all. data must be valid JSON, as it is when
+// called from UnmarshalJSON.
+func decodeObject(data []byte, target layoutHolder, plain interface{}, previous *objectLayout) error {
+	if previous != nil {
+		clearLayouts(reflect.ValueOf(target))
+	}
+	if err := json.Unmarshal(data, plain); err != nil {
+		return (&decodeState{}).decode(data, target)
+	}
+	return nil
+}
+
+// UnmarshalLossless decodes data like json.Unmarshal but in lossless mode:
+// target and every object inside it remember the members they were read from,
+// and MarshalJSON writes unchanged members back byte for byte, keeps unknown
+// members and only re-encodes the fields that were edited. target must point
+// to one of the package's object types, such as *Recording or *ClientJSON.
+// Decoding a Recording or typed client again with json.Unmarshal forgets the
+// layout.
+func UnmarshalLossless(data []byte, target interface{}) error {
+	holder, isHolder := target.(layoutHolder)
+	if !isHolder {
+		return fmt.Errorf("%T does not support lossless decoding", target)
+	}
+	if !json.Valid(data) {
+		var syntaxCheck json.RawMessage
+		return json.Unmarshal(data, &syntaxCheck)
+	}
+	if client, isClient := holder.(typedClient); isClient {
+		if err := checkClientType(data, client); err != nil {
+			return err
+		}
+	}
+	return (&decodeState{lossless: true}).decode(data, holder)
+}
+
+func (ds *decodeState) decode(data []byte, target layoutHolder) error {
+	stream := &valueStream{data: data, decoder: json.NewDecoder(bytes.NewReader(data))}
+	return ds.member(stream, stream.valueStart(), reflect.ValueOf(target).Elem())
+}
+
+// object decodes the object starting at start, whose opening brace has not
+// been read yet.
+func (ds *decodeState) object(stream *valueStream, start int64, target layoutHolder) error {
+	targetValue := reflect.ValueOf(target).Elem()
+	if _, err := stream.decoder.Token(); err != nil {
+		return err
+	}
+
+	var layout *objectLayout
+	if ds.lossless {
+		layout = &objectLayout{members: []rawMember{}}
+	}
+	for stream.decoder.More() {
+		token, err := stream.decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, isString := token.(string)
+		if !isString {
+			return errors.New("expected a JSON object key")
+		}
+		memberStart := stream.valueStart()
+		fieldIndex, wasFound := fieldIndexByJSONName(targetValue.Type(), key)
+		if !wasFound {
+			raw, err := stream.skip()
+			if err != nil {
+				return err
+			}
+			if layout != nil {
+				layout.members = append(layout.members, rawMember{Key: key, Value: raw, Offset: memberStart - start})
+			}
+			continue
+		}
+
+		field := targetValue.Field(fieldIndex)
+		err = ds.located(key, memberStart-start, stream, memberStart, func(child *decodeState) error {
+			return child.member(stream, memberStart, field)
+		})
+		if err != nil {
+			return err
+		}
+		if layout != nil {
+			// The caller may reuse data once the decode returns, so copy it.
+			raw := append(json.RawMessage(nil), stream.data[memberStart:stream.decoder.InputOffset()]...)
+			layout.members = append(layout.members, rawMember{Key: key, Value: raw, Offset: memberStart - start, decoded: copyValue(field)})
+		}
+	}
+	if _, err := stream.decoder.Token(); err != nil {
+		return err
+	}
+	target.setLayout(layout)
+	return nil
+}
+
+// located runs decode for the value found under key at offset and roots its
+// error and warnings at the current object.
+func (ds *decodeState) located(key string, offset int64, stream *valueStream, start int64, decode func(child *decodeState) error) error {
+	child := &decodeState{lenient: ds.lenient, lossless: ds.lossless}
+	err := decode(child)
+	raw := func() []byte {
+		return stream.data[start:stream.decoder.InputOffset()]
+	}
+	for _, warning := range child.warnings {
+		ds.warnings = append(ds.warnings, wrapMemberError(warning, key, offset, raw()))
+	}
+	if err == nil {
+		return nil
+	}
+	locatedError := wrapMemberError(err, key, offset, raw())
+	if !ds.lenient {
+		return locatedError
+	}
+	ds.warnings = append(ds.warnings, locatedError)
+	return nil
+}
+
+// member decodes the value starting at start into field. Layout holders are
+// decoded from the stream with the same state, and slices of them element by
+// element, so errors can name the field and index they came from. The whole
+// value is always read, even when it cannot be decoded.
+func (ds *decodeState) member(stream *valueStream, start int64, field reflect.Value) error {
+	var first byte
+	if start < int64(len(stream.data)) {
+		first = stream.data[start]
+	}
+	if field.Addr().Type().Implements(layoutHolderType) {
+		if first == '{' {
+			return ds.object(stream, start, field.Addr().Interface().(layoutHolder))
+		}
+		raw, err := stream.skip()
+		if err != nil || bytes.Equal(raw, []byte("null")) {
+			return err
+		}
+		return errors.New("expected a JSON object")
+	}
+	if field.Kind() == reflect.Slice && reflect.PtrTo(field.Type().Elem()).Implements(layoutHolderType) {
+		if first == '[' {
+			return ds.array(stream, field)
+		}
+		raw, err := stream.skip()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(raw, []byte("null")) {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		return errors.New("expected a JSON array")
+	}
+
+	raw, err := stream.skip()
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw, field.Addr().Interface())
+	if err != nil && ds.lenient {
+		return coerceValue(raw, field, err)
+	}
+	return err
+}
+
+// array decodes the array of layout holders starting at the next token.
+func (ds *decodeState) array(stream *valueStream, field reflect.Value) error {
+	start := stream.valueStart()
+	if _, err := stream.decoder.Token(); err != nil {
+		return err
+	}
+
+	elements := reflect.MakeSlice(field.Type(), 0, 0)
+	for index := 0; stream.decoder.More(); index++ {
+		elementStart := stream.valueStart()
+		elementValue := reflect.New(field.Type().Elem())
+		err := ds.located(fmt.Sprintf("[%d]", index), elementStart-start, stream, elementStart, func(child *decodeState) error {
+			return child.member(stream, elementStart, elementValue.Elem())
+		})
+		if err != nil {
+			return err
+		}
+		elements = reflect.Append(elements, elementValue.Elem())
+	}
+	if _, err := stream.decoder.Token(); err != nil {
+		return err
+	}
+	field.Set(elements)
+	return nil
+}
+
+// copyValue returns a copy of value that shares no slices with it, so editing
+// an element in place, such as Images[0].ImageID, is seen as a change.
+func copyValue(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+	deepenCopy(copied)
+	return copied
+}
+
+func deepenCopy(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.IsNil() {
+			return
+		}
+		fresh := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(fresh, value)
+		for i := 0; i < fresh.Len(); i++ {
+			deepenCopy(fresh.Index(i))
+		}
+		value.Set(fresh)
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			deepenCopy(value.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).PkgPath == "" {
+				deepenCopy(value.Field(i))
+			}
+		}
+	}
+}
+
+// encodeWithLayout writes structure back in the member order of layout. Members
+// whose field still holds the decoded value are copied verbatim, changed fields
+// are re-encoded, unknown members are passed through, and fields that were not
+// in the original object are appended when they are no longer empty.
+func encodeWithLayout(layout *objectLayout, structure interface{}) ([]byte, error) {
+	structureValue := reflect.ValueOf(structure)
+	structureType := structureValue.Type()
+	encoder := newObjectEncoder(structure)
+	written := make(map[int]bool)
+
+	for _, member := range layout.members {
+		fieldIndex, wasFound := fieldIndexByJSONName(structureType, member.Key)
+		if !wasFound {
+			encoder.RawMember(member.Key, member.Value)
+			continue
+		}
+		written[fieldIndex] = true
+		fieldValue := structureValue.Field(fieldIndex)
+		if member.decoded.IsValid() && reflect.DeepEqual(member.decoded.Interface(), fieldValue.Interface()) {
+			encoder.RawMember(member.Key, member.Value)
+			continue
+		}
+		jsonData, err := marshalLikeRaw(member.Key, fieldValue, member.Value)
+		if err != nil {
+			return nil, err
+		}
+		encoder.RawMember(member.Key, jsonData)
+	}
+
+	for i := 0; i < structureType.NumField(); i++ {
+		jsonFieldName := jsonTagName(structureType.Field(i))
+		if written[i] || jsonFieldName == "" || structureValue.Field(i).IsZero() {
+			continue
+		}
+		jsonData, err := marshalWithoutHTMLEscape(structureValue.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		encoder.RawMember(jsonFieldName, jsonData)
+	}
+	return encoder.Bytes()
+}
+
+// marshalLikeRaw encodes a changed field, keeping the number of decimals the
+// original used for floats so 1800.0 does not turn into 1800.
+func marshalLikeRaw(key string, fieldValue reflect.Value, raw json.RawMessage) ([]byte, error) {
+	switch fieldValue.Kind() {
+	case reflect.Float32, reflect.Float64:
+		rawNumber := string(raw)
+		if dot := strings.IndexByte(rawNumber, '.'); dot >= 0 && !strings.ContainsAny(rawNumber, "eE") {
+			decimals := len(rawNumber) - dot - 1
+			if err := checkFloat(key, fieldValue.Float()); err != nil {
+				return nil, err
+			}
+			return []byte(strconv.FormatFloat(fieldValue.Float(), 'f', decimals, fieldValue.Type().Bits())), nil
+		}
+	}
+	return marshalWithoutHTMLEscape(fieldValue.Interface())
+}
+
+// fieldIndexByJSONName finds the exported field tagged key, falling back to a
+// case-insensitive match the way encoding/json does.
+func fieldIndexByJSONName(structType reflect.Type, key string) (int, bool) {
+	foldedIndex := -1
+	for i := 0; i < structType.NumField(); i++ {
+		jsonFieldName := jsonTagName(structType.Field(i))
+		if jsonFieldName == "" {
+			continue
+		}
+		if jsonFieldName == key {
+			return i, true
+		}
+		if foldedIndex < 0 && strings.EqualFold(jsonFieldName, key) {
+			foldedIndex = i
+		}
+	}
+	return foldedIndex, foldedIndex >= 0
+}
+
+func jsonTagName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	jsonFieldName := strings.Split(field.Tag.Get("json"), ",")[0]
+	if jsonFieldName == "-" {
+		return ""
+	}
+	if jsonFieldName == "" {
+		return field.Name
+	}
+	return jsonFieldName
+}
+
+// clearLayouts drops the remembered layout from value and everything inside it.
+func clearLayouts(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			clearLayouts(value.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			clearLayouts(value.Index(i))
+		}
+	case reflect.Struct:
+		if value.CanAddr() {
+			if holder, isHolder := value.Addr().Interface().(layoutHolder); isHolder {
+				holder.setLayout(nil)
+			}
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).PkgPath == "" {
+				clearLayouts(value.Field(i))
+			}
+		}
+	}
+}
+
+// ClearLayout forgets the member order and unknown members remembered from
+// decoding, so MarshalJSON writes the canonical Tablo layout instead.
+func (tr *Recording) ClearLayout() {
+	clearLayouts(reflect.ValueOf(tr))
+}
@@ -0,0 +1,163 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+const extendedEpisodeJSON = `{"recChannel":{"jsonForClient":{"type":"recChannel","channel":{"callSign":"KXAS-HD","major":5,"minor":1,"network":"NBC"},"objectID":185238}},"recEpisode":{"imageJson":{"images":[{"type":"image","imageID":353557,"imageType":"snapshot","imageStyle":"snapshot","width":1280,"hasTitle":false}],"version":2},"jsonForClient":{"type":"recEpisode","title":"Law & \"Order\"","description":"Line one\nline two","episodeNumber":10,"seasonNumber":1,"airDate":"2017-09-19T05:00Z","originalAirDate":"2017-09-18","scheduleDuration":3600.00,"qualifiers":["cc","hd"],"relationships":{"recSeason":301535,"recSeries":301534,"recChannel":185238,"recProgram":77},"video":{"state":"finished","size":5302616064,"width":1920,"height":1080,"duration":5417.0,"scheduleOffsetStart":-15.0,"scheduleOffsetEnd":1805.0,"audio":"ac3"},"user":{"type":"recordingUserInfo","watched":false,"protected":false,"position":0.0},"objectID":343176,"firmwareField":[1,{"nested":true}]}},"recSeries":{"jsonForClient":{"title":"Midnight, Texas","relationships":{"genres":[108]},"objectID":301534,"type":"recSeries"}},"recSeason":{"jsonForClient":{"seasonNumber":1,"relationships":{"recSeries":301534},"objectID":301535,"type":"recSeason"}},"exportedBy":"tablo 2.2.26"}`
+
+func TestLosslessRoundTrip(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := tablometadata.UnmarshalLossless([]byte(extendedEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if recording.RecordedEpisode.JSONForClient.Title != `Law & "Order"` {
+		t.Fatalf("unexpected title %q", recording.RecordedEpisode.JSONForClient.Title)
+	}
+
+	jsonData, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(jsonData) != extendedEpisodeJSON {
+		t.Errorf("round trip changed the document:\n got %s\nwant %s", jsonData, extendedEpisodeJSON)
+	}
+}
+
+func TestLosslessRoundTripAfterEdit(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := tablometadata.UnmarshalLossless([]byte(extendedEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	recording.RecordedEpisode.JSONForClient.User.Watched = true
+	recording.RecordedEpisode.JSONForClient.ScheduleDuration = 1800
+	recording.RecordedEpisode.ImageJSON.Images[0].ImageID = 1
+
+	jsonData, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.NewReplacer(
+		`"watched":false`, `"watched":true`,
+		`"scheduleDuration":3600.00`, `"scheduleDuration":1800.00`,
+		`"imageID":353557`, `"imageID":1`,
+	).Replace(extendedEpisodeJSON)
+	if string(jsonData) != want {
+		t.Errorf("edit was not applied losslessly:\n got %s\nwant %s", jsonData, want)
+	}
+}
+
+func TestClearLayout(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := tablometadata.UnmarshalLossless([]byte(extendedEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	recording.ClearLayout()
+
+	jsonData, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unknown := range []string{"network", "firmwareField", "exportedBy", "hasTitle", "audio"} {
+		if strings.Contains(string(jsonData), unknown) {
+			t.Errorf("expected %s to be dropped from %s", unknown, jsonData)
+		}
+	}
+	if !strings.HasPrefix(string(jsonData), `{"recEpisode":{"jsonForClient":{"type":"recEpisode"`) {
+		t.Errorf("expected canonical key order, got %s", jsonData)
+	}
+}
+
+func TestUnmarshalKeepsNoLayout(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(extendedEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(jsonData), "firmwareField") || !strings.HasPrefix(string(jsonData), `{"recEpisode":{"jsonForClient":{"type":"recEpisode"`) {
+		t.Errorf("expected json.Unmarshal to decode without a layout, got %s", jsonData)
+	}
+
+	if err := tablometadata.UnmarshalLossless([]byte(`{"recEpisode":{]`), &recording); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
+
+func TestUnmarshalForgetsLosslessLayout(t *testing.T) {
+	var client tablometadata.EpisodeClient
+	if err := tablometadata.UnmarshalLossless([]byte(`{"objectID":1,"extra":5}`), &client); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"objectID":7}`), &client); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := json.Marshal(&client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(jsonData), "extra") || !strings.Contains(string(jsonData), `"objectID":7`) {
+		t.Errorf("expected the reused client to drop its old layout, got %s", jsonData)
+	}
+
+	var recording tablometadata.Recording
+	if err := tablometadata.UnmarshalLossless([]byte(extendedEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if jsonData, err = recording.MarshalJSON(); err != nil {
+		t.Fatal(err)
+	}
+	for _, unknown := range []string{"firmwareField", "exportedBy", "hasTitle"} {
+		if strings.Contains(string(jsonData), unknown) {
+			t.Errorf("expected the reused recording to drop %s, got %s", unknown, jsonData)
+		}
+	}
+}
+
+func TestUnmarshalRecordingAllocations(t *testing.T) {
+	data := []byte(sampleEpisodeJSON)
+	allocations := testing.AllocsPerRun(100, func() {
+		var recording tablometadata.Recording
+		if err := json.Unmarshal(data, &recording); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// The default decode is a plain json.Unmarshal; the member by member
+	// decoder used for lossless and lenient decoding allocates hundreds of times.
+	if allocations > 40 {
+		t.Errorf("json.Unmarshal of a recording allocated %.0f times", allocations)
+	}
+}
+
+func BenchmarkUnmarshalLossless(b *testing.B) {
+	data := []byte(extendedEpisodeJSON)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var recording tablometadata.Recording
+		if err := tablometadata.UnmarshalLossless(data, &recording); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLosslessRejectsNaN(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := tablometadata.UnmarshalLossless([]byte(extendedEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	recording.RecordedEpisode.JSONForClient.ScheduleDuration = float32(math.NaN())
+	if _, err := recording.MarshalJSON(); err == nil {
+		t.Error("expected a NaN schedule duration to fail")
+	}
+}
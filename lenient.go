@@ -0,0 +1,100 @@
+package tablometadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeOptions controls DecodeRecording. Source names the document in the
+// ParseErrors it returns, usually the meta file path. Lossless decodes the way
+// UnmarshalLossless does.
+type DecodeOptions struct {
+	Lenient  bool
+	Lossless bool
+	Source   string
+}
+
+// DecodeRecording decodes a meta file. Strict decoding behaves like
+// json.Unmarshal. In lenient mode a member that cannot be decoded no longer
+// fails the whole recording: numbers written as strings, integers written as
+// 3600.0 and similar mismatches are coerced, anything else is left at its zero
+// value, and each one is returned as a warning located by its JSON path. Only
+// a document that is not valid JSON fails in lenient mode.
+func DecodeRecording(data []byte, options DecodeOptions) (Recording, []*ParseError, error) {
+	var recording Recording
+	if !options.Lenient && !options.Lossless {
+		if err := json.Unmarshal(data, &recording); err != nil {
+			return Recording{}, nil, newParseError(options.Source, err)
+		}
+		return recording, nil, nil
+	}
+	if !json.Valid(data) {
+		var syntaxCheck json.RawMessage
+		err := json.Unmarshal(data, &syntaxCheck)
+		return recording, nil, newParseError(options.Source, err)
+	}
+
+	ds := &decodeState{lenient: options.Lenient, lossless: options.Lossless}
+	if err := ds.decode(data, &recording); err != nil {
+		return Recording{}, nil, newParseError(options.Source, err)
+	}
+	for _, warning := range ds.warnings {
+		warning.Source = options.Source
+	}
+	return recording, ds.warnings, nil
+}
+
+// coerceValue is the lenient fallback for a value json.Unmarshal rejected. It
+// converts between numbers, numeric strings and booleans where that loses no
+// information and otherwise zeroes field. The returned error becomes the
+// warning, so it is never nil.
+func coerceValue(raw json.RawMessage, field reflect.Value, err error) error {
+	text := strings.TrimSpace(string(raw))
+	if unquoted, unquoteErr := strconv.Unquote(text); unquoteErr == nil && bytes.HasPrefix(raw, []byte(`"`)) {
+		text = strings.TrimSpace(unquoted)
+	}
+
+	coerced := reflect.New(field.Type()).Elem()
+	wasCoerced := false
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number, parseErr := strconv.ParseFloat(text, 64); parseErr == nil && number == float64(int64(number)) && !coerced.OverflowInt(int64(number)) {
+			coerced.SetInt(int64(number))
+			wasCoerced = true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, parseErr := strconv.ParseFloat(text, 64); parseErr == nil && number >= 0 && number == float64(uint64(number)) && !coerced.OverflowUint(uint64(number)) {
+			coerced.SetUint(uint64(number))
+			wasCoerced = true
+		}
+	case reflect.Float32, reflect.Float64:
+		if number, parseErr := strconv.ParseFloat(text, field.Type().Bits()); parseErr == nil {
+			coerced.SetFloat(number)
+			wasCoerced = true
+		}
+	case reflect.Bool:
+		switch strings.ToLower(text) {
+		case "true", "1":
+			coerced.SetBool(true)
+			wasCoerced = true
+		case "false", "0":
+			wasCoerced = true
+		}
+	case reflect.String:
+		if len(raw) > 0 && raw[0] != '{' && raw[0] != '[' && text != "null" {
+			coerced.SetString(text)
+			wasCoerced = true
+		}
+	}
+
+	if !wasCoerced {
+		field.Set(reflect.Zero(field.Type()))
+		return err
+	}
+	field.Set(coerced)
+	return fmt.Errorf("%w; coerced to %v", err, coerced.Interface())
+}
@@ -0,0 +1,105 @@
+package tablometadata_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func lenientEpisodeJSON() string {
+	data := strings.Replace(sampleEpisodeJSON, `"duration":3600,`, `"duration":3600.0,`, 1)
+	data = strings.Replace(data, `"episodeNumber":10`, `"episodeNumber":"10"`, 1)
+	return strings.Replace(data, `"airDate":"2017-09-19T05:00Z"`, `"airDate":"Tuesday night"`, 1)
+}
+
+func TestDecodeRecordingLenientCollectsWarnings(t *testing.T) {
+	data := lenientEpisodeJSON()
+	recording, warnings, err := tablometadata.DecodeRecording([]byte(data), tablometadata.DecodeOptions{Lenient: true, Source: "meta.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	episode := recording.RecordedEpisode.JSONForClient
+	if recording.RecordedSeries.JSONForClient.Duration != 3600 || episode.EpisodeNumber != 10 {
+		t.Errorf("expected coerced values, got duration %d and episode %d", recording.RecordedSeries.JSONForClient.Duration, episode.EpisodeNumber)
+	}
+	if episode.Title != "The Virgin Sacrifice" || !episode.AirDate.StoredTime.IsZero() {
+		t.Errorf("expected the rest of the episode with a zero airDate, got %+v", episode)
+	}
+
+	paths := make(map[string]*tablometadata.ParseError)
+	for _, warning := range warnings {
+		paths[warning.Path] = warning
+		if warning.Source != "meta.txt" {
+			t.Errorf("expected the source on %v", warning)
+		}
+		if located := data[warning.Offset : warning.Offset+int64(len(warning.Value))]; located != warning.Value {
+			t.Errorf("offset %d points at %q", warning.Offset, located)
+		}
+	}
+	if len(warnings) != 3 {
+		t.Errorf("expected three warnings, got %v", warnings)
+	}
+	for _, path := range []string{"recSeries.jsonForClient.duration", "recEpisode.jsonForClient.episodeNumber", "recEpisode.jsonForClient.airDate"} {
+		if _, wasFound := paths[path]; !wasFound {
+			t.Errorf("expected a warning for %s, got %v", path, warnings)
+		}
+	}
+	if warning := paths["recEpisode.jsonForClient.airDate"]; warning != nil && !errors.Is(warning, tablometadata.ErrUnknownDatePattern) {
+		t.Errorf("expected ErrUnknownDatePattern, got %v", warning.Err)
+	}
+}
+
+func TestDecodeRecordingStrictFails(t *testing.T) {
+	_, _, err := tablometadata.DecodeRecording([]byte(lenientEpisodeJSON()), tablometadata.DecodeOptions{Source: "meta.txt"})
+	var parseError *tablometadata.ParseError
+	if !errors.As(err, &parseError) || parseError.Source != "meta.txt" {
+		t.Errorf("expected a ParseError, got %v", err)
+	}
+
+	if _, _, err := tablometadata.DecodeRecording([]byte(`{"recEpisode":{]`), tablometadata.DecodeOptions{Lenient: true}); err == nil {
+		t.Error("expected invalid JSON to fail in lenient mode")
+	}
+}
+
+func TestDecodeRecordingLenientRoundTrip(t *testing.T) {
+	data := lenientEpisodeJSON()
+	recording, _, err := tablometadata.DecodeRecording([]byte(data), tablometadata.DecodeOptions{Lenient: true, Lossless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != data {
+		t.Errorf("expected the malformed members to be kept\n got %s\nwant %s", encoded, data)
+	}
+}
+
+func TestLoadLibraryLenient(t *testing.T) {
+	root := t.TempDir()
+	writeMetaFile(t, root, 343176, lenientEpisodeJSON())
+
+	library, err := tablometadata.LoadLibrary(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(library.Recordings) != 0 || len(library.Errors) != 1 {
+		t.Fatalf("expected a strict load error, got %+v", library)
+	}
+
+	library, err = tablometadata.LoadLibraryWithOptions(context.Background(), root, tablometadata.LoadOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(library.Recordings) != 1 || len(library.Errors) != 0 {
+		t.Fatalf("expected the recording to load, got %+v", library.Errors)
+	}
+	if warnings := library.Recordings[0].Warnings; len(warnings) != 3 || warnings[0].Source != library.Recordings[0].Path {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+}
@@ -0,0 +1,266 @@
+package tablometadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	TABLORECDIR   = "rec"
+	TABLOMETAFILE = "meta.txt"
+)
+
+// LibraryRecording is a single decoded meta file from a Tablo storage tree.
+// Warnings lists the members a lenient load could not decode as written.
+type LibraryRecording struct {
+	Path      string
+	ObjectID  int
+	Recording Recording
+	Warnings  []*ParseError
+}
+
+// LoadError records a meta file that could not be read or decoded.
+type LoadError struct {
+	Path string
+	Err  error
+}
+
+func (le LoadError) Error() string {
+	return fmt.Sprintf("%s: %v", le.Path, le.Err)
+}
+
+func (le LoadError) Unwrap() error {
+	return le.Err
+}
+
+// Library is the inventory of every recording found under a storage root.
+type Library struct {
+	Root       string
+	Recordings []LibraryRecording
+	Errors     []LoadError
+}
+
+// LoadResult is one meta file delivered by StreamLibrary. Err is set when the
+// file could not be read or decoded.
+type LoadResult struct {
+	Path      string
+	Recording LibraryRecording
+	Err       error
+}
+
+// LoadOptions configures LoadLibraryWithOptions and StreamLibraryWithOptions.
+// A Workers value below one uses GOMAXPROCS. Lenient decodes every meta file
+// with DecodeOptions.Lenient, so malformed members become warnings on the
+// LibraryRecording rather than load errors. Lossless keeps each file's layout
+// for MarshalJSON, at the cost of holding on to its raw members.
+type LoadOptions struct {
+	Workers  int
+	Lenient  bool
+	Lossless bool
+}
+
+var errStopWalk = errors.New("walk stopped")
+
+// LoadLibrary walks root for rec/<objectID>/meta.txt files and decodes each one.
+// Files that fail to load are collected in Errors and do not stop the scan; the
+// returned error is only set when root itself cannot be walked.
+func LoadLibrary(root string) (*Library, error) {
+	return LoadLibraryContext(context.Background(), root, 0)
+}
+
+// LoadLibraryContext is LoadLibrary with a bounded pool of decoding workers and
+// cancellation. Recordings and Errors are sorted by path regardless of the order
+// the workers finish in.
+func LoadLibraryContext(ctx context.Context, root string, workers int) (*Library, error) {
+	return LoadLibraryWithOptions(ctx, root, LoadOptions{Workers: workers})
+}
+
+// LoadLibraryWithOptions is LoadLibraryContext with every load option.
+func LoadLibraryWithOptions(ctx context.Context, root string, options LoadOptions) (*Library, error) {
+	results, err := StreamLibraryWithOptions(ctx, root, options)
+	if err != nil {
+		return nil, err
+	}
+
+	library := &Library{Root: root}
+	var rootErr error
+	for result := range results {
+		if result.Err != nil && result.Path == root {
+			rootErr = result.Err
+			continue
+		}
+		if result.Err != nil {
+			library.Errors = append(library.Errors, LoadError{Path: result.Path, Err: result.Err})
+			continue
+		}
+		library.Recordings = append(library.Recordings, result.Recording)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if rootErr != nil {
+		return nil, rootErr
+	}
+
+	sort.Slice(library.Recordings, func(i, j int) bool {
+		return library.Recordings[i].Path < library.Recordings[j].Path
+	})
+	sort.Slice(library.Errors, func(i, j int) bool {
+		return library.Errors[i].Path < library.Errors[j].Path
+	})
+	return library, nil
+}
+
+// StreamLibrary walks root and decodes meta files on workers goroutines, sending
+// each result on the returned channel as soon as it is ready. A workers value
+// below one uses GOMAXPROCS. The channel is closed once every file has been
+// delivered or ctx is cancelled; callers that stop reading early must cancel ctx.
+// An error walking root itself is sent with Path set to root; LoadLibrary
+// returns it as its error.
+func StreamLibrary(ctx context.Context, root string, workers int) (<-chan LoadResult, error) {
+	return StreamLibraryWithOptions(ctx, root, LoadOptions{Workers: workers})
+}
+
+// StreamLibraryWithOptions is StreamLibrary with every load option.
+func StreamLibraryWithOptions(ctx context.Context, root string, options LoadOptions) (<-chan LoadResult, error) {
+	if err := checkLibraryRoot(root); err != nil {
+		return nil, err
+	}
+	workers := options.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	paths := make(chan string, workers)
+	results := make(chan LoadResult, workers)
+
+	go func() {
+		defer close(paths)
+		err := walkMetaFiles(root, func(path string) error {
+			select {
+			case paths <- path:
+				return nil
+			case <-ctx.Done():
+				return errStopWalk
+			}
+		}, func(path string, err error) error {
+			select {
+			case results <- LoadResult{Path: path, Err: err}:
+				return nil
+			case <-ctx.Done():
+				return errStopWalk
+			}
+		})
+		if err != nil && err != errStopWalk {
+			select {
+			case results <- LoadResult{Path: root, Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer waitGroup.Done()
+			for path := range paths {
+				if ctx.Err() != nil {
+					return
+				}
+				libraryRecording, err := loadMetaFile(path, options)
+				select {
+				case results <- LoadResult{Path: path, Recording: libraryRecording, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		waitGroup.Wait()
+		close(results)
+	}()
+	return results, nil
+}
+
+func checkLibraryRoot(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+	return nil
+}
+
+// walkMetaFiles calls visit for every meta file under root. An entry below root
+// that cannot be read is passed to skip and the walk carries on without it;
+// only an error on root itself, or from visit or skip, stops the walk.
+func walkMetaFiles(root string, visit func(path string) error, skip func(path string, err error) error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// An unreadable entry should not abort the whole inventory.
+			if skipErr := skip(path, err); skipErr != nil {
+				return skipErr
+			}
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isMetaFilePath(path, entry) {
+			return visit(path)
+		}
+		return nil
+	})
+}
+
+func isMetaFilePath(path string, entry fs.DirEntry) bool {
+	if entry.IsDir() || entry.Name() != TABLOMETAFILE {
+		return false
+	}
+	objectDir := filepath.Dir(path)
+	if _, err := objectIDFromDir(objectDir); err != nil {
+		return false
+	}
+	return filepath.Base(filepath.Dir(objectDir)) == TABLORECDIR
+}
+
+func objectIDFromDir(dir string) (int, error) {
+	return strconv.Atoi(filepath.Base(dir))
+}
+
+func loadMetaFile(path string, options LoadOptions) (LibraryRecording, error) {
+	var libraryRecording LibraryRecording
+	objectID, err := objectIDFromDir(filepath.Dir(path))
+	if err != nil {
+		return libraryRecording, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return libraryRecording, err
+	}
+	recording, warnings, err := DecodeRecording(data, DecodeOptions{Lenient: options.Lenient, Lossless: options.Lossless, Source: path})
+	if err != nil {
+		return libraryRecording, err
+	}
+	libraryRecording.Path = path
+	libraryRecording.ObjectID = objectID
+	libraryRecording.Recording = recording
+	libraryRecording.Warnings = warnings
+	return libraryRecording, nil
+}
@@ -0,0 +1,285 @@
+package tablometadata_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+const (
+	sampleMovieJSON   = `{"recMovieAiring":{"jsonForClient":{"type":"recMovieAiring","objectID":117665,"airDate":"2016-11-06T23:00Z","scheduleDuration":7200.0,"relationships":{"recMovie":117666,"recChannel":5465},"video":{"state":"finished","size":3415293952,"width":1280,"height":720,"duration":7520.0,"scheduleOffsetStart":-15.0,"scheduleOffsetEnd":304.0},"user":{"type":"recordingUserInfo","watched":false,"protected":false,"position":0.0}},"imageJson":{"images":[{"type":"image","imageID":123122,"imageType":"snapshot","imageStyle":"snapshot"}]}},"recMovie":{"jsonForClient":{"title":"Buying the Cow","plot":"A man hits the dating scene.","runtime":5160,"mpaaRating":"r","releaseYear":2001,"cast":["Jerry O'Connell"],"directors":["Walt Becker"],"qualityRating":0.250,"relationships":{"genres":[1063]},"type":"recMovie","objectID":117666},"imageJson":{"images":[{"type":"image","imageID":114189,"imageType":"movie_2x3_small","imageStyle":"thumbnail"}]}},"recChannel":{"jsonForClient":{"type":"recChannel","channel":{"callSign":"KVCW","major":33,"minor":1},"objectID":5465}}}`
+	sampleEpisodeJSON = `{"recEpisode":{"jsonForClient":{"type":"recEpisode","title":"The Virgin Sacrifice","description":"Manfred leads the Midnighters.","episodeNumber":10,"seasonNumber":1,"airDate":"2017-09-19T05:00Z","originalAirDate":"2017-09-18","scheduleDuration":3600,"qualifiers":["cc"],"relationships":{"recSeason":301535,"recSeries":301534,"recChannel":185238},"video":{"state":"finished","size":5302616064,"width":1920,"height":1080,"duration":5417.0,"scheduleOffsetStart":-15.0,"scheduleOffsetEnd":1805.0},"user":{"type":"recordingUserInfo","watched":false,"protected":false,"position":0.0},"objectID":343176},"imageJson":{"images":[{"type":"image","imageID":353557,"imageType":"snapshot","imageStyle":"snapshot"}]}},"recSeries":{"jsonForClient":{"title":"Midnight, Texas","description":"A haven for vampires.","originalAirDate":"2017-07-24","duration":3600,"cast":["Dylan Bruce"],"relationships":{"genres":[108]},"objectID":301534,"type":"recSeries"},"imageJson":{"images":[{"type":"image","imageID":290612,"imageType":"series_3x4_small","imageStyle":"thumbnail"}]}},"recSeason":{"jsonForClient":{"seasonNumber":1,"relationships":{"recSeries":301534},"objectID":301535,"type":"recSeason"}},"recChannel":{"jsonForClient":{"type":"recChannel","channel":{"callSign":"KXAS-HD","major":5,"minor":1},"objectID":185238}}}`
+)
+
+// episodeAiredOn returns sampleEpisodeJSON as the meta file of objectID, aired
+// at airDate, with edits applied to the decoded structs in order.
+func episodeAiredOn(t *testing.T, objectID int, airDate string, edits ...func(recording *tablometadata.Recording)) tablometadata.LibraryRecording {
+	t.Helper()
+	airTime, err := time.Parse(tablometadata.TABLODATELAYOUT, airDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	libraryRecording := decodeLibraryRecording(t, fmt.Sprintf("rec/%d/meta.txt", objectID), sampleEpisodeJSON)
+	libraryRecording.ObjectID = objectID
+	episode := &libraryRecording.Recording.RecordedEpisode.JSONForClient
+	episode.ObjectID = objectID
+	episode.AirDate.StoredTime = airTime
+	for _, edit := range edits {
+		edit(&libraryRecording.Recording)
+	}
+	return libraryRecording
+}
+
+// numbered makes the episode episodeNumber of season seasonNumber, bundled with
+// a recSeason of its own.
+func numbered(seasonNumber int, episodeNumber int) func(recording *tablometadata.Recording) {
+	return func(recording *tablometadata.Recording) {
+		seasonID := 400000 + seasonNumber
+		episode := &recording.RecordedEpisode.JSONForClient
+		episode.EpisodeNumber = episodeNumber
+		episode.SeasonNumber = seasonNumber
+		episode.Relationships.RecSeason = seasonID
+		season := &recording.RecordedSeason.JSONForClient
+		season.SeasonNumber = seasonNumber
+		season.ObjectID = seasonID
+	}
+}
+
+// unnumbered drops the episode and season numbers from the episode itself.
+func unnumbered(recording *tablometadata.Recording) {
+	recording.RecordedEpisode.JSONForClient.EpisodeNumber = 0
+	recording.RecordedEpisode.JSONForClient.SeasonNumber = 0
+}
+
+func writeMetaFile(t testing.TB, root string, objectID int, contents string) string {
+	t.Helper()
+	dir := filepath.Join(root, "rec", strconv.Itoa(objectID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "meta.txt")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLibrary(t *testing.T) {
+	root := t.TempDir()
+	writeMetaFile(t, filepath.Join(root, "driveA"), 117665, sampleMovieJSON)
+	writeMetaFile(t, filepath.Join(root, "driveB"), 343176, sampleEpisodeJSON)
+	badPath := writeMetaFile(t, filepath.Join(root, "driveB"), 400000, `{"recEpisode":`)
+	if err := os.WriteFile(filepath.Join(root, "driveA", "rec", "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	library, err := tablometadata.LoadLibrary(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(library.Recordings) != 2 {
+		t.Fatalf("expected 2 recordings, got %d", len(library.Recordings))
+	}
+	if len(library.Errors) != 1 || library.Errors[0].Path != badPath {
+		t.Fatalf("expected one load error for %s, got %v", badPath, library.Errors)
+	}
+
+	movie := library.Recordings[0]
+	if movie.ObjectID != 117665 || movie.Recording.RecordedMovie.JSONForClient.Title != "Buying the Cow" {
+		t.Errorf("unexpected movie recording %+v", movie)
+	}
+	episode := library.Recordings[1]
+	if episode.ObjectID != 343176 || episode.Recording.RecordedSeries.JSONForClient.Title != "Midnight, Texas" {
+		t.Errorf("unexpected episode recording %+v", episode)
+	}
+}
+
+func TestLoadLibraryReportsUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	root := t.TempDir()
+	writeMetaFile(t, root, 343176, sampleEpisodeJSON)
+	lockedRoot := filepath.Join(root, "driveB")
+	writeMetaFile(t, lockedRoot, 117665, sampleMovieJSON)
+	lockedDir := filepath.Join(lockedRoot, "rec")
+	if err := os.Chmod(lockedDir, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(lockedDir, 0755) })
+
+	library, err := tablometadata.LoadLibrary(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(library.Recordings) != 1 {
+		t.Errorf("expected the readable recording, got %d", len(library.Recordings))
+	}
+	if len(library.Errors) != 1 || library.Errors[0].Path != lockedDir {
+		t.Errorf("expected a load error for %s, got %v", lockedDir, library.Errors)
+	}
+}
+
+func TestLoadLibraryReturnsRootWalkError(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	root := t.TempDir()
+	writeMetaFile(t, root, 343176, sampleEpisodeJSON)
+	if err := os.Chmod(root, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(root, 0755) })
+
+	if library, err := tablometadata.LoadLibrary(root); err == nil {
+		t.Fatalf("expected the root walk error to be returned, got %+v", library)
+	}
+}
+
+func TestLoadLibraryMissingRoot(t *testing.T) {
+	if _, err := tablometadata.LoadLibrary(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected an error for a missing root")
+	}
+}
+
+func TestLoadLibraryContextCancelled(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 20; i++ {
+		writeMetaFile(t, root, 500000+i, sampleEpisodeJSON)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tablometadata.LoadLibraryContext(ctx, root, 4); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStreamLibraryCancelledDecodesNothing(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 20; i++ {
+		writeMetaFile(t, root, 500000+i, sampleEpisodeJSON)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := tablometadata.StreamLibrary(ctx, root, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for result := range results {
+		t.Errorf("expected no results after cancelling, got %s", result.Path)
+	}
+}
+
+func TestStreamLibrary(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 50; i++ {
+		writeMetaFile(t, root, 600000+i, sampleMovieJSON)
+	}
+	writeMetaFile(t, root, 700000, `not json`)
+
+	results, err := tablometadata.StreamLibrary(context.Background(), root, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded, failed int
+	for result := range results {
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		if result.Recording.Path != result.Path {
+			t.Errorf("result path %s does not match recording path %s", result.Path, result.Recording.Path)
+		}
+		loaded++
+	}
+	if loaded != 50 || failed != 1 {
+		t.Fatalf("expected 50 loaded and 1 failed, got %d and %d", loaded, failed)
+	}
+}
+
+const benchmarkCorpusSize = 50000
+
+var (
+	benchmarkCorpusOnce sync.Once
+	benchmarkCorpusRoot string
+	benchmarkCorpusErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchmarkCorpusRoot != "" {
+		os.RemoveAll(benchmarkCorpusRoot)
+	}
+	os.Exit(code)
+}
+
+func benchmarkCorpus(b *testing.B) string {
+	b.Helper()
+	benchmarkCorpusOnce.Do(func() {
+		benchmarkCorpusRoot, benchmarkCorpusErr = os.MkdirTemp("", "tablometa-bench")
+		if benchmarkCorpusErr != nil {
+			return
+		}
+		for i := 0; i < benchmarkCorpusSize; i++ {
+			contents := sampleEpisodeJSON
+			if i%4 == 0 {
+				contents = sampleMovieJSON
+			}
+			dir := filepath.Join(benchmarkCorpusRoot, "rec", strconv.Itoa(1000000+i))
+			if benchmarkCorpusErr = os.MkdirAll(dir, 0755); benchmarkCorpusErr != nil {
+				return
+			}
+			if benchmarkCorpusErr = os.WriteFile(filepath.Join(dir, "meta.txt"), []byte(contents), 0644); benchmarkCorpusErr != nil {
+				return
+			}
+		}
+	})
+	if benchmarkCorpusErr != nil {
+		b.Fatal(benchmarkCorpusErr)
+	}
+	return benchmarkCorpusRoot
+}
+
+func benchmarkLoadLibrary(b *testing.B, workers int) {
+	root := benchmarkCorpus(b)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		library, err := tablometadata.LoadLibraryContext(context.Background(), root, workers)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(library.Recordings) != benchmarkCorpusSize {
+			b.Fatalf("expected %d recordings, got %d", benchmarkCorpusSize, len(library.Recordings))
+		}
+	}
+	b.ReportMetric(float64(benchmarkCorpusSize*b.N)/time.Since(start).Seconds(), "files/s")
+}
+
+func BenchmarkLoadLibrarySequential(b *testing.B) {
+	benchmarkLoadLibrary(b, 1)
+}
+
+func BenchmarkLoadLibraryParallel(b *testing.B) {
+	benchmarkLoadLibrary(b, 0)
+}
+
+func BenchmarkUnmarshalRecording(b *testing.B) {
+	data := []byte(sampleEpisodeJSON)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var recording tablometadata.Recording
+		if err := json.Unmarshal(data, &recording); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
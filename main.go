@@ -1,45 +1,41 @@
 package tablometadata
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
+	"bytes"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	TABLODATEPATTERN         = `"[0-9]+-(0?[1-9]|[1][0-2])-[0-9]+T(0?[0-9]|1[0-9]|2[0-3]):[0-9]+Z"`
-	RFC3339PATTERN           = `"[0-9]+-(0?[1-9]|[1][0-2])-[0-9]+T(0?[0-9]|1[0-9]|2[0-3]):[0-9]+:[0-9]+\.[0-9]+Z"`
-	CLIENTRECAIRINGMOVIEFMT  = `{"%s":"%s","%s":%d,"%s":%s,"%s":%.1f,"%s":%s,"%s":%s,"%s":%s} `
-	CLIENTRECMOVIE           = `{"%s":"%s","%s":"%s","%s":%d,"%s":"%s","%s":%d,"%s":%s,"%s":%s,"%s":%.3f,"%s":%s,"%s":"%s","%s":%d}`
-	CLIENTRECEPISODE         = `{"%s":"%s","%s":"%s","%s":"%s","%s":%d,"%s":%d,"%s":%s,"%s":"%s","%s":%0.0f,"%s":%s,"%s":%s,"%s":%s,"%s":%s,"%s":%d}`
-	CLIENTRECSERIES          = `{"%s":"%s","%s":"%s","%s":"%s","%s":%d,"%s":%s,"%s":%s,"%s":%d,"%s":"%s"}`
-	CLIENTRECSEASONS         = `{"%s":%d,"%s":%s,"%s":%d,"%s":"%s"}`
-	RECMOVIEFMT              = `{"%s":%s,"%s":%s}`
-	RELATIONSHIPSMOVIESFMT   = `{"%s":%d,"%s":%d}`
-	RELATIONSHIPSEPISODESFMT = `{"%s":%d,"%s":%d,"%s":%d}`
-	RELATIONSHIPSGENRESFMT   = `{"%s":%s}`
-	RELATIONSHIPSSERIESFMT   = `{"%s":%d}`
-	RECORDINGMOVIEFMT        = `{"%s":%s,"%s":%s}`
-	VIDEOINFOFMT             = `{"%s":"%s","%s":%d,"%s":%d,"%s":%d,"%s":%.1f,"%s":%.1f,"%s":%.1f}`
-	USERINFOFMT              = `{"%s":"%s","%s":%t,"%s":%t,"%s":%.1f}`
-	RECORDINGEPISDEFMT       = `{"%s":%s,"%s":%s,"%s":%s}`
+	TABLODATELAYOUT    = "2006-01-02T15:04Z"
+	EPOCHSECONDSLAYOUT = "epoch"
+)
+
+const (
+	TIMESTAMPPATTERN = `^"([0-9]+-[0-9]+-[0-9]+T[0-9]+:[0-9]+)(:[0-9]+(\.[0-9]+)?)?(Z|[+-][0-9]{2}:?[0-9]{2})"$`
+	EPOCHPATTERN     = `^-?[0-9]+$`
+)
+
+var (
+	timestampPattern    = regexp.MustCompile(TIMESTAMPPATTERN)
+	epochSecondsPattern = regexp.MustCompile(EPOCHPATTERN)
 )
 
 func getJSONFieldNameByName(structureOfInterest interface{}, fieldName string) (string, error) {
-	episodeField, wasFound := reflect.TypeOf(structureOfInterest).FieldByName(fieldName)
+	structureType := reflect.TypeOf(structureOfInterest)
+	episodeField, wasFound := structureType.FieldByName(fieldName)
 	if wasFound {
 		jsonFieldName := episodeField.Tag.Get("json")
 		if len(jsonFieldName) < 1 {
-			return "", errors.New("json tag not found")
+			return "", &FieldLookupError{TypeName: structureType.Name(), FieldName: fieldName, Err: ErrJSONTagNotFound}
 		} else {
 			return jsonFieldName, nil
 		}
 	} else {
-		return "", errors.New("field not found")
+		return "", &FieldLookupError{TypeName: structureType.Name(), FieldName: fieldName, Err: ErrFieldNotFound}
 	}
 }
 
@@ -47,8 +43,12 @@ type TabloType interface {
 	GetTabloType() string
 }
 
+// TabloDate is a timestamp as Tablo writes it. Layout is the time layout the
+// value was read from, or EPOCHSECONDSLAYOUT for a bare number of seconds, and
+// MarshalJSON writes the same form back; an empty Layout writes TABLODATELAYOUT.
 type TabloDate struct {
 	StoredTime time.Time
+	Layout     string
 }
 
 func (tt TabloDate) Format(layout string) string {
@@ -56,23 +56,65 @@ func (tt TabloDate) Format(layout string) string {
 }
 
 func (tt *TabloDate) UnmarshalJSON(data []byte) error {
-	var rfcPattern = regexp.MustCompile(RFC3339PATTERN)
-	var tabloPattern = regexp.MustCompile(TABLODATEPATTERN)
-	if rfcPattern.Match(data) {
-		return tt.StoredTime.UnmarshalJSON(data)
-	} else if tabloPattern.Match(data) {
-		workingDateString := string(data[:])
-		workingDateString = strings.TrimSuffix(workingDateString, `Z"`)
-		workingDateString = workingDateString + `:00.00Z"`
-		return tt.StoredTime.UnmarshalJSON([]byte(workingDateString))
-	} else {
-		return errors.New("unknown date pattern")
+	if epochSecondsPattern.Match(data) {
+		seconds, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return &ParseError{Value: string(data), Err: err}
+		}
+		tt.StoredTime = time.Unix(seconds, 0).UTC()
+		tt.Layout = EPOCHSECONDSLAYOUT
+		return nil
+	}
+
+	parts := timestampPattern.FindSubmatch(data)
+	if parts == nil {
+		return &ParseError{Value: string(data), Err: ErrUnknownDatePattern}
+	}
+	layout := "2006-01-02T15:04"
+	if len(parts[2]) > 0 {
+		layout += ":05"
+	}
+	if len(parts[3]) > 0 {
+		layout += "." + strings.Repeat("0", len(parts[3])-1)
 	}
+	switch zone := parts[4]; {
+	case bytes.Equal(zone, []byte("Z")):
+		layout += "Z"
+	case bytes.IndexByte(zone, ':') > 0:
+		layout += "-07:00"
+	default:
+		layout += "-0700"
+	}
+
+	text := string(data[1 : len(data)-1])
+	storedTime, err := time.Parse(layout, text)
+	if err != nil {
+		return &ParseError{Value: string(data), Err: err}
+	}
+	tt.StoredTime = storedTime
+	tt.Layout = layout
+	return nil
 }
 
 func (tt TabloDate) MarshalJSON() ([]byte, error) {
-	jsonString := fmt.Sprintf(`"%s"`, tt.StoredTime.Format("2006-01-02T15:04Z"))
-	return []byte(jsonString), nil
+	layout := tt.Layout
+	if len(layout) < 1 {
+		layout = TABLODATELAYOUT
+	}
+	if layout == EPOCHSECONDSLAYOUT {
+		return []byte(strconv.FormatInt(tt.StoredTime.Unix(), 10)), nil
+	}
+
+	storedTime := tt.StoredTime
+	if strings.HasSuffix(layout, "Z") {
+		// A literal Z in the layout means the value is written in UTC.
+		storedTime = storedTime.UTC()
+	}
+	var buffer bytes.Buffer
+	if err := writeJSONString(&buffer, storedTime.Format(layout)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
 }
 
 type Relationships struct {
@@ -81,107 +123,75 @@ type Relationships struct {
 	Genres     []int `json:"genres"`
 	RecSeason  int   `json:"recSeason"`
 	RecSeries  int   `json:"recSeries"`
-}
-
-func (tr Relationships) MarshalJSON() ([]byte, error) {
-	var jsonData []byte
-	var jsonString string
-	recChannelFieldName, err := getJSONFieldNameByName(tr, "RecChannel")
-	if err != nil {
-		return nil, err
-	}
-	recSeriesFieldName, err := getJSONFieldNameByName(tr, "RecSeries")
-	if err != nil {
-		return nil, err
-	}
-	if tr.RecMovie > 0 {
-		recMovieFieldName, err := getJSONFieldNameByName(tr, "RecMovie")
-		if err != nil {
-			return nil, err
-		}
 
-		jsonString = fmt.Sprintf(RELATIONSHIPSMOVIESFMT, recMovieFieldName, tr.RecMovie, recChannelFieldName, tr.RecChannel)
+	RecSportOrganization int   `json:"recSportOrganization"`
+	RecSportTeams        []int `json:"recSportTeams"`
 
-	} else if tr.RecSeason > 0 {
-
-		recSeasonFieldName, err := getJSONFieldNameByName(tr, "RecSeason")
-		if err != nil {
-			return nil, err
-		}
-
-		jsonString = fmt.Sprintf(RELATIONSHIPSEPISODESFMT, recSeasonFieldName, tr.RecSeason, recSeriesFieldName, tr.RecSeries, recChannelFieldName, tr.RecChannel)
-
-	} else if tr.RecSeries > 0 {
-
-		jsonString = fmt.Sprintf(RELATIONSHIPSSERIESFMT, recSeriesFieldName, tr.RecSeries)
-
-	}
-	if tr.Genres != nil && len(tr.Genres) > 0 {
-		genreJSONData, err := json.Marshal(tr.Genres)
-		if err != nil {
-			return nil, err
-		}
-		genresFieldName, err := getJSONFieldNameByName(tr, "Genres")
-		if err != nil {
-			return nil, err
-		}
-		jsonString = fmt.Sprintf(RELATIONSHIPSGENRESFMT, genresFieldName, string(genreJSONData[:]))
-
-	}
-	jsonData = append(jsonData, []byte(jsonString)...)
-	return jsonData, nil
+	layout *objectLayout
 }
 
-type VideoInfo struct {
-	State               string  `json:"state"`
-	Size                uint64  `json:"size"`
-	Width               int     `json:"width"`
-	Height              int     `json:"height"`
-	Duration            float32 `json:"duration"`
-	ScheduleOffsetStart float32 `json:"scheduleOffsetStart"`
-	ScheduleOffsetEnd   float32 `json:"scheduleOffsetEnd"`
+func (tr *Relationships) setLayout(layout *objectLayout) {
+	tr.layout = layout
 }
 
-func (vr VideoInfo) MarshalJSON() ([]byte, error) {
-
-	var jsonData []byte
-	stateFieldName, err := getJSONFieldNameByName(vr, "State")
-	if err != nil {
-		return nil, err
+func (tr Relationships) MarshalJSON() ([]byte, error) {
+	if tr.layout != nil {
+		return encodeWithLayout(tr.layout, tr)
 	}
-
-	sizeFieldName, err := getJSONFieldNameByName(vr, "Size")
-	if err != nil {
-		return nil, err
+	encoder := newObjectEncoder(tr)
+	if tr.RecMovie > 0 {
+		encoder.Int("RecMovie", tr.RecMovie)
 	}
-	widthFieldName, err := getJSONFieldNameByName(vr, "Width")
-	if err != nil {
-		return nil, err
+	if tr.RecSeason > 0 {
+		encoder.Int("RecSeason", tr.RecSeason)
 	}
-	heightFieldName, err := getJSONFieldNameByName(vr, "Height")
-	if err != nil {
-		return nil, err
+	if tr.RecSeries > 0 {
+		encoder.Int("RecSeries", tr.RecSeries)
 	}
-	durationFieldName, err := getJSONFieldNameByName(vr, "Duration")
-	if err != nil {
-		return nil, err
+	if tr.RecSportOrganization > 0 {
+		encoder.Int("RecSportOrganization", tr.RecSportOrganization)
 	}
-
-	scheduleOffsetStartFieldName, err := getJSONFieldNameByName(vr, "ScheduleOffsetStart")
-	if err != nil {
-		return nil, err
+	if len(tr.RecSportTeams) > 0 {
+		encoder.Value("RecSportTeams", tr.RecSportTeams)
 	}
-
-	scheduleOffsetEndFieldName, err := getJSONFieldNameByName(vr, "ScheduleOffsetEnd")
-	if err != nil {
-		return nil, err
+	if tr.RecChannel > 0 {
+		encoder.Int("RecChannel", tr.RecChannel)
+	}
+	if len(tr.Genres) > 0 {
+		encoder.Value("Genres", tr.Genres)
 	}
+	return encoder.Bytes()
+}
 
-	jsonString := fmt.Sprintf(VIDEOINFOFMT, stateFieldName, vr.State, sizeFieldName, vr.Size, widthFieldName, vr.Width, heightFieldName, vr.Height,
-		durationFieldName, vr.Duration, scheduleOffsetStartFieldName, vr.ScheduleOffsetStart, scheduleOffsetEndFieldName, vr.ScheduleOffsetEnd)
-	jsonData = append(jsonData, []byte(jsonString)...)
+type VideoInfo struct {
+	State               RecordingState `json:"state"`
+	Size                uint64         `json:"size"`
+	Width               int            `json:"width"`
+	Height              int            `json:"height"`
+	Duration            float32        `json:"duration"`
+	ScheduleOffsetStart float32        `json:"scheduleOffsetStart"`
+	ScheduleOffsetEnd   float32        `json:"scheduleOffsetEnd"`
+
+	layout *objectLayout
+}
 
-	return jsonData, nil
+func (vr *VideoInfo) setLayout(layout *objectLayout) {
+	vr.layout = layout
+}
+
+func (vr VideoInfo) MarshalJSON() ([]byte, error) {
+	if vr.layout != nil {
+		return encodeWithLayout(vr.layout, vr)
+	}
+	encoder := newObjectEncoder(vr)
+	encoder.String("State", string(vr.State))
+	encoder.Uint("Size", vr.Size)
+	encoder.Int("Width", vr.Width)
+	encoder.Int("Height", vr.Height)
+	encoder.Float("Duration", vr.Duration, 1)
+	encoder.Float("ScheduleOffsetStart", vr.ScheduleOffsetStart, 1)
+	encoder.Float("ScheduleOffsetEnd", vr.ScheduleOffsetEnd, 1)
+	return encoder.Bytes()
 }
 
 type UserInfo struct {
@@ -189,32 +199,24 @@ type UserInfo struct {
 	Watched   bool    `json:"watched"`
 	Protected bool    `json:"protected"`
 	Position  float32 `json:"position"`
-}
-
-func (ur UserInfo) MarshalJSON() ([]byte, error) {
 
-	var jsonData []byte
-	usertypeFieldName, err := getJSONFieldNameByName(ur, "UserType")
-	if err != nil {
-		return nil, err
-	}
+	layout *objectLayout
+}
 
-	watchedFieldName, err := getJSONFieldNameByName(ur, "Watched")
-	if err != nil {
-		return nil, err
-	}
-	protectedFieldName, err := getJSONFieldNameByName(ur, "Protected")
-	if err != nil {
-		return nil, err
-	}
-	positionFieldName, err := getJSONFieldNameByName(ur, "Position")
-	if err != nil {
-		return nil, err
-	}
-	jsonString := fmt.Sprintf(USERINFOFMT, usertypeFieldName, ur.UserType, watchedFieldName, ur.Watched, protectedFieldName, ur.Protected, positionFieldName, ur.Position)
-	jsonData = append(jsonData, []byte(jsonString)...)
+func (ur *UserInfo) setLayout(layout *objectLayout) {
+	ur.layout = layout
+}
 
-	return jsonData, nil
+func (ur UserInfo) MarshalJSON() ([]byte, error) {
+	if ur.layout != nil {
+		return encodeWithLayout(ur.layout, ur)
+	}
+	encoder := newObjectEncoder(ur)
+	encoder.String("UserType", ur.UserType)
+	encoder.Bool("Watched", ur.Watched)
+	encoder.Bool("Protected", ur.Protected)
+	encoder.Float("Position", ur.Position, 1)
+	return encoder.Bytes()
 }
 
 type ImageData struct {
@@ -222,10 +224,43 @@ type ImageData struct {
 	ImageID    int    `json:"imageID"`
 	ImageType  string `json:"imageType"`
 	ImageStyle string `json:"imageStyle"`
+
+	layout *objectLayout
+}
+
+func (id *ImageData) setLayout(layout *objectLayout) {
+	id.layout = layout
+}
+
+func (id ImageData) MarshalJSON() ([]byte, error) {
+	if id.layout != nil {
+		return encodeWithLayout(id.layout, id)
+	}
+	encoder := newObjectEncoder(id)
+	encoder.String("Type", id.Type)
+	encoder.Int("ImageID", id.ImageID)
+	encoder.String("ImageType", id.ImageType)
+	encoder.String("ImageStyle", id.ImageStyle)
+	return encoder.Bytes()
 }
 
 type ImageJSONData struct {
 	Images []ImageData `json:"images"`
+
+	layout *objectLayout
+}
+
+func (ij *ImageJSONData) setLayout(layout *objectLayout) {
+	ij.layout = layout
+}
+
+func (ij ImageJSONData) MarshalJSON() ([]byte, error) {
+	if ij.layout != nil {
+		return encodeWithLayout(ij.layout, ij)
+	}
+	encoder := newObjectEncoder(ij)
+	encoder.Value("Images", ij.Images)
+	return encoder.Bytes()
 }
 
 type ClientJSON struct {
@@ -247,191 +282,85 @@ type ClientJSON struct {
 	Description      string        `json:"description"`
 	EpisodeNumber    int           `json:"episodeNumber"`
 	SeasonNumber     int           `json:"seasonNumber"`
-	OriginalAirDate  string        `json:"originalAirDate"`
+	OriginalAirDate  CivilDate     `json:"originalAirDate"`
 	Qualifiers       []string      `json:"qualifiers"`
 	Duration         int           `json:"duration"`
-}
-
-func (tr ClientJSON) MarshalJSON() ([]byte, error) {
-
-	var jsonData []byte
-	var jsonString string
-
-	typeFieldName, err := getJSONFieldNameByName(tr, "Type")
-	if err != nil {
-		return nil, err
-	}
-
-	relationshipsFieldName, err := getJSONFieldNameByName(tr, "Relationships")
-	if err != nil {
-		return nil, err
-	}
 
-	relationshipJSONData, err := tr.Relationships.MarshalJSON()
-	if err != nil {
-		return nil, err
-	}
-
-	objectIDFieldName, err := getJSONFieldNameByName(tr, "ObjectID")
-	if err != nil {
-		return nil, err
-	}
-
-	titleFieldName, err := getJSONFieldNameByName(tr, "Title")
-	if err != nil {
-		return nil, err
-	}
-
-	airDateFieldName, err := getJSONFieldNameByName(tr, "AirDate")
-	if err != nil {
-		return nil, err
-	}
-
-	airDateData, err := tr.AirDate.MarshalJSON()
-	if err != nil {
-		return nil, err
-	}
-
-	scheduleDurationFieldName, err := getJSONFieldNameByName(tr, "ScheduleDuration")
-	if err != nil {
-		return nil, err
-	}
-
-	videoFieldName, err := getJSONFieldNameByName(tr, "Video")
-	if err != nil {
-		return nil, err
-	}
-
-	videoJSONData, err := json.Marshal(tr.Video)
-	if err != nil {
-		return nil, err
-	}
-
-	userFieldName, err := getJSONFieldNameByName(tr, "User")
-	if err != nil {
-		return nil, err
-	}
-
-	userJSONData, err := json.Marshal(tr.User)
-	if err != nil {
-		return nil, err
-	}
-	descriptionFieldName, err := getJSONFieldNameByName(tr, "Description")
-	if err != nil {
-		return nil, err
-	}
-	descriptionString := strings.Replace(tr.Description, `"`, `\"`, -1)
-
-	castFieldName, err := getJSONFieldNameByName(tr, "Cast")
-	if err != nil {
-		return nil, err
-	}
-
-	castJSONData, err := json.Marshal(tr.Cast)
-	if err != nil {
-		return nil, err
-	}
-
-	originalAirDateFieldName, err := getJSONFieldNameByName(tr, "OriginalAirDate")
-	if err != nil {
-		return nil, err
-	}
-
-	if tr.Type == "recMovieAiring" {
-		//type objecid airdate
-
-		jsonString = fmt.Sprintf(CLIENTRECAIRINGMOVIEFMT, typeFieldName, tr.Type, objectIDFieldName, tr.ObjectID, airDateFieldName, string(airDateData[:]), scheduleDurationFieldName,
-			tr.ScheduleDuration, relationshipsFieldName, string(relationshipJSONData[:]), videoFieldName, string(videoJSONData[:]), userFieldName, userJSONData)
-
-	} else if tr.Type == "recMovie" {
-
-		plotFieldName, err := getJSONFieldNameByName(tr, "Plot")
-		if err != nil {
-			return nil, err
-		}
-		runtimeFieldName, err := getJSONFieldNameByName(tr, "Runtime")
-		if err != nil {
-			return nil, err
-		}
-		mpaaRatingFieldName, err := getJSONFieldNameByName(tr, "MPAARating")
-		if err != nil {
-			return nil, err
-		}
-		releaseYearFieldName, err := getJSONFieldNameByName(tr, "ReleaseYear")
-		if err != nil {
-			return nil, err
-		}
-
-		directorsFieldName, err := getJSONFieldNameByName(tr, "Directors")
-		if err != nil {
-			return nil, err
-		}
-		directorsJSONData, err := json.Marshal(tr.Directors)
-		if err != nil {
-			return nil, err
-		}
-		qualityRationFieldName, err := getJSONFieldNameByName(tr, "QualityRating")
-		if err != nil {
-			return nil, err
-		}
-
-		jsonString = fmt.Sprintf(CLIENTRECMOVIE, titleFieldName, tr.Title, plotFieldName, tr.Plot, runtimeFieldName, tr.Runtime,
-			mpaaRatingFieldName, tr.MPAARating, releaseYearFieldName, tr.ReleaseYear, castFieldName, string(castJSONData[:]), directorsFieldName, directorsJSONData,
-			qualityRationFieldName, tr.QualityRating, relationshipsFieldName, relationshipJSONData, typeFieldName, tr.Type, objectIDFieldName, tr.ObjectID)
-
-	} else if tr.Type == "recEpisode" {
-
-		episodeNumberFieldName, err := getJSONFieldNameByName(tr, "EpisodeNumber")
-		if err != nil {
-			return nil, err
-		}
-		seasonNumberFieldName, err := getJSONFieldNameByName(tr, "SeasonNumber")
-		if err != nil {
-			return nil, err
-		}
-
-		qualifiersFieldName, err := getJSONFieldNameByName(tr, "Qualifiers")
-		if err != nil {
-			return nil, err
-		}
-		qualfiersData, err := json.Marshal(tr.Qualifiers)
-		if err != nil {
-			return nil, err
-		}
-
-		jsonString = fmt.Sprintf(CLIENTRECEPISODE, typeFieldName, tr.Type, titleFieldName, tr.Title, descriptionFieldName, descriptionString, episodeNumberFieldName, tr.EpisodeNumber,
-			seasonNumberFieldName, tr.SeasonNumber, airDateFieldName, string(airDateData[:]), originalAirDateFieldName, tr.OriginalAirDate, scheduleDurationFieldName, tr.ScheduleDuration,
-			qualifiersFieldName, string(qualfiersData[:]), relationshipsFieldName, relationshipJSONData, videoFieldName, string(videoJSONData[:]), userFieldName, string(userJSONData[:]),
-			objectIDFieldName, tr.ObjectID)
-
-	} else if tr.Type == "recSeries" {
-		durationFieldName, err := getJSONFieldNameByName(tr, "Duration")
-		if err != nil {
-			return nil, err
-		}
-		jsonString = fmt.Sprintf(CLIENTRECSERIES, titleFieldName, tr.Title, descriptionFieldName, descriptionString, originalAirDateFieldName, tr.OriginalAirDate,
-			durationFieldName, tr.Duration, castFieldName, string(castJSONData[:]),
-			relationshipsFieldName, relationshipJSONData,
-			objectIDFieldName, tr.ObjectID, typeFieldName, tr.Type)
-
-	} else if tr.Type == "recSeason" {
-		seasonNumberFieldName, err := getJSONFieldNameByName(tr, "SeasonNumber")
-		if err != nil {
-			return nil, err
-		}
-		jsonString = fmt.Sprintf(CLIENTRECSEASONS, seasonNumberFieldName, tr.SeasonNumber,
-			relationshipsFieldName, relationshipJSONData,
-			objectIDFieldName, tr.ObjectID, typeFieldName, tr.Type)
+	layout *objectLayout
+}
 
-	}
+func (tr *ClientJSON) setLayout(layout *objectLayout) {
+	tr.layout = layout
+}
 
-	jsonData = append(jsonData, []byte(jsonString)...)
-	return jsonData, nil
+func (tr ClientJSON) MarshalJSON() ([]byte, error) {
+	if tr.layout != nil {
+		return encodeWithLayout(tr.layout, tr)
+	}
+	encoder := newObjectEncoder(tr)
+	switch tr.Type {
+	case "recMovieAiring":
+		return tr.MovieAiringClient().MarshalJSON()
+	case "recMovie":
+		return tr.MovieClient().MarshalJSON()
+	case "recEpisode":
+		return tr.EpisodeClient().MarshalJSON()
+	case "recSeries":
+		return tr.SeriesClient().MarshalJSON()
+	case "recSeason":
+		return tr.SeasonClient().MarshalJSON()
+	case "recSportOrganization", "recSportTeam":
+		encoder.String("Title", tr.Title)
+		encoder.String("Description", tr.Description)
+		encoder.Value("Relationships", tr.Relationships)
+		encoder.Int("ObjectID", tr.ObjectID)
+		encoder.String("Type", tr.Type)
+	case "recManualProgram":
+		encoder.String("Type", tr.Type)
+		encoder.String("Title", tr.Title)
+		encoder.Value("AirDate", tr.AirDate)
+		encoder.Float("ScheduleDuration", tr.ScheduleDuration, 0)
+		encoder.Value("Relationships", tr.Relationships)
+		encoder.Value("Video", tr.Video)
+		encoder.Value("User", tr.User)
+		encoder.Int("ObjectID", tr.ObjectID)
+	case "recProgram":
+		encoder.String("Type", tr.Type)
+		encoder.String("Title", tr.Title)
+		encoder.String("Description", tr.Description)
+		encoder.Value("AirDate", tr.AirDate)
+		encoder.Value("OriginalAirDate", tr.OriginalAirDate)
+		encoder.Float("ScheduleDuration", tr.ScheduleDuration, 0)
+		encoder.Value("Qualifiers", tr.Qualifiers)
+		encoder.Value("Relationships", tr.Relationships)
+		encoder.Value("Video", tr.Video)
+		encoder.Value("User", tr.User)
+		encoder.Int("ObjectID", tr.ObjectID)
+	default:
+		encoder.Populated()
+	}
+	return encoder.Bytes()
 }
 
 type MovieAiring struct {
 	JSONForClient ClientJSON    `json:"jsonForClient"`
 	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (ma *MovieAiring) setLayout(layout *objectLayout) {
+	ma.layout = layout
+}
+
+func (ma MovieAiring) MarshalJSON() ([]byte, error) {
+	if ma.layout != nil {
+		return encodeWithLayout(ma.layout, ma)
+	}
+	encoder := newObjectEncoder(ma)
+	encoder.Value("JSONForClient", ma.JSONForClient)
+	encoder.Value("ImageJSON", ma.ImageJSON)
+	return encoder.Bytes()
 }
 
 func (ma *MovieAiring) GetTabloType() string {
@@ -441,6 +370,22 @@ func (ma *MovieAiring) GetTabloType() string {
 type RecMovie struct {
 	JSONForClient ClientJSON    `json:"jsonForClient"`
 	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (rm *RecMovie) setLayout(layout *objectLayout) {
+	rm.layout = layout
+}
+
+func (rm RecMovie) MarshalJSON() ([]byte, error) {
+	if rm.layout != nil {
+		return encodeWithLayout(rm.layout, rm)
+	}
+	encoder := newObjectEncoder(rm)
+	encoder.Value("JSONForClient", rm.JSONForClient)
+	encoder.Value("ImageJSON", rm.ImageJSON)
+	return encoder.Bytes()
 }
 
 func (rm *RecMovie) GetTabloType() string {
@@ -450,6 +395,22 @@ func (rm *RecMovie) GetTabloType() string {
 type RecSeries struct {
 	JSONForClient ClientJSON    `json:"jsonForClient"`
 	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (rs *RecSeries) setLayout(layout *objectLayout) {
+	rs.layout = layout
+}
+
+func (rs RecSeries) MarshalJSON() ([]byte, error) {
+	if rs.layout != nil {
+		return encodeWithLayout(rs.layout, rs)
+	}
+	encoder := newObjectEncoder(rs)
+	encoder.Value("JSONForClient", rs.JSONForClient)
+	encoder.Value("ImageJSON", rs.ImageJSON)
+	return encoder.Bytes()
 }
 
 func (rs *RecSeries) GetTabloType() string {
@@ -458,6 +419,21 @@ func (rs *RecSeries) GetTabloType() string {
 
 type RecSeason struct {
 	JSONForClient ClientJSON `json:"jsonForClient"`
+
+	layout *objectLayout
+}
+
+func (rs *RecSeason) setLayout(layout *objectLayout) {
+	rs.layout = layout
+}
+
+func (rs RecSeason) MarshalJSON() ([]byte, error) {
+	if rs.layout != nil {
+		return encodeWithLayout(rs.layout, rs)
+	}
+	encoder := newObjectEncoder(rs)
+	encoder.Value("JSONForClient", rs.JSONForClient)
+	return encoder.Bytes()
 }
 
 func (rs *RecSeason) GetTabloType() string {
@@ -467,6 +443,22 @@ func (rs *RecSeason) GetTabloType() string {
 type RecEpisode struct {
 	JSONForClient ClientJSON    `json:"jsonForClient"`
 	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (re *RecEpisode) setLayout(layout *objectLayout) {
+	re.layout = layout
+}
+
+func (re RecEpisode) MarshalJSON() ([]byte, error) {
+	if re.layout != nil {
+		return encodeWithLayout(re.layout, re)
+	}
+	encoder := newObjectEncoder(re)
+	encoder.Value("JSONForClient", re.JSONForClient)
+	encoder.Value("ImageJSON", re.ImageJSON)
+	return encoder.Bytes()
 }
 
 func (re *RecEpisode) GetTabloType() string {
@@ -479,69 +471,59 @@ type Recording struct {
 	RecordedSeason  RecSeason   `json:"recSeason"`
 	Airing          MovieAiring `json:"recMovieAiring"`
 	RecordedMovie   RecMovie    `json:"recMovie"`
-}
 
-func (tr Recording) MarshalJSON() ([]byte, error) {
-	var jsonData []byte
+	RecordedSportEvent        RecSportEvent        `json:"recSportEvent"`
+	RecordedSportOrganization RecSportOrganization `json:"recSportOrganization"`
+	RecordedSportTeams        []RecSportTeam       `json:"recSportTeams"`
 
-	if len(tr.Airing.GetTabloType()) > 0 {
-		airingFieldName, err := getJSONFieldNameByName(tr, "Airing")
-		if err != nil {
-			return nil, err
-		}
+	RecordedManualProgram RecManualProgram `json:"recManualProgram"`
+	RecordedProgram       RecProgram       `json:"recProgram"`
 
-		airingJSONData, err := json.Marshal(tr.Airing)
-		if err != nil {
-			return nil, err
-		}
+	RecordedChannel RecChannel `json:"recChannel"`
 
-		recMovieFieldName, err := getJSONFieldNameByName(tr, "RecordedMovie")
-		if err != nil {
-			return nil, err
-		}
+	layout *objectLayout
+}
 
-		recMovieJSONData, err := json.Marshal(tr.RecordedMovie)
-		if err != nil {
-			return nil, err
-		}
-		jsonString := fmt.Sprintf(RECORDINGMOVIEFMT, airingFieldName, string(airingJSONData[:]), recMovieFieldName, string(recMovieJSONData[:]))
+func (tr *Recording) setLayout(layout *objectLayout) {
+	tr.layout = layout
+}
+
+func (tr *Recording) UnmarshalJSON(data []byte) error {
+	type plain Recording
+	return decodeObject(data, tr, (*plain)(tr), tr.layout)
+}
 
-		jsonData = append(jsonData, []byte(jsonString)...)
+func (tr Recording) MarshalJSON() ([]byte, error) {
+	if tr.layout != nil {
+		return encodeWithLayout(tr.layout, tr)
+	}
+	encoder := newObjectEncoder(tr)
+	if len(tr.Airing.GetTabloType()) > 0 {
+		encoder.Value("Airing", tr.Airing)
+		encoder.Value("RecordedMovie", tr.RecordedMovie)
 	}
 	if len(tr.RecordedEpisode.GetTabloType()) > 0 {
-		recEpisodeFieldName, err := getJSONFieldNameByName(tr, "RecordedEpisode")
-		if err != nil {
-			return nil, err
+		encoder.Value("RecordedEpisode", tr.RecordedEpisode)
+		encoder.Value("RecordedSeries", tr.RecordedSeries)
+		encoder.Value("RecordedSeason", tr.RecordedSeason)
+	}
+	if len(tr.RecordedSportEvent.GetTabloType()) > 0 {
+		encoder.Value("RecordedSportEvent", tr.RecordedSportEvent)
+		if len(tr.RecordedSportOrganization.GetTabloType()) > 0 {
+			encoder.Value("RecordedSportOrganization", tr.RecordedSportOrganization)
 		}
-
-		recEpisodeJSONData, err := json.Marshal(tr.RecordedEpisode)
-		if err != nil {
-			return nil, err
+		if len(tr.RecordedSportTeams) > 0 {
+			encoder.Value("RecordedSportTeams", tr.RecordedSportTeams)
 		}
-
-		recSeriesFieldName, err := getJSONFieldNameByName(tr, "RecordedSeries")
-		if err != nil {
-			return nil, err
-		}
-
-		recSeriesJSONData, err := json.Marshal(tr.RecordedSeries)
-		if err != nil {
-			return nil, err
-		}
-
-		recSeasonFieldName, err := getJSONFieldNameByName(tr, "RecordedSeason")
-		if err != nil {
-			return nil, err
-		}
-
-		recSeasonJSONData, err := json.Marshal(tr.RecordedSeason)
-		if err != nil {
-			return nil, err
-		}
-
-		jsonString := fmt.Sprintf(RECORDINGEPISDEFMT, recEpisodeFieldName, string(recEpisodeJSONData[:]), recSeriesFieldName, string(recSeriesJSONData[:]), recSeasonFieldName, string(recSeasonJSONData[:]))
-
-		jsonData = append(jsonData, []byte(jsonString)...)
 	}
-	return jsonData, nil
+	if len(tr.RecordedManualProgram.GetTabloType()) > 0 {
+		encoder.Value("RecordedManualProgram", tr.RecordedManualProgram)
+	}
+	if len(tr.RecordedProgram.GetTabloType()) > 0 {
+		encoder.Value("RecordedProgram", tr.RecordedProgram)
+	}
+	if len(tr.RecordedChannel.GetTabloType()) > 0 {
+		encoder.Value("RecordedChannel", tr.RecordedChannel)
+	}
+	return encoder.Bytes()
 }
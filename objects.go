@@ -0,0 +1,318 @@
+package tablometadata
+
+import "fmt"
+
+// TabloObject is implemented by every object wrapper so generic code can read
+// the common parts of an object without a type assertion.
+type TabloObject interface {
+	TabloType
+	ObjectID() int
+	DisplayTitle() string
+	Relationships() Relationships
+	Images() []ImageData
+	AirTime() (TabloDate, bool)
+}
+
+var (
+	_ TabloObject = (*MovieAiring)(nil)
+	_ TabloObject = (*RecMovie)(nil)
+	_ TabloObject = (*RecSeries)(nil)
+	_ TabloObject = (*RecSeason)(nil)
+	_ TabloObject = (*RecEpisode)(nil)
+	_ TabloObject = (*RecSportEvent)(nil)
+	_ TabloObject = (*RecSportOrganization)(nil)
+	_ TabloObject = (*RecSportTeam)(nil)
+	_ TabloObject = (*RecManualProgram)(nil)
+	_ TabloObject = (*RecProgram)(nil)
+	_ TabloObject = (*RecChannel)(nil)
+)
+
+// clientOf returns the jsonForClient of one of the package's own wrappers, or
+// the zero value for a TabloObject implemented elsewhere.
+func clientOf(object TabloObject) ClientJSON {
+	if holder, isHolder := object.(interface{ client() ClientJSON }); isHolder {
+		return holder.client()
+	}
+	return ClientJSON{}
+}
+
+func (tr ClientJSON) displayTitle() string {
+	switch tr.Type {
+	case "recSeason":
+		return fmt.Sprintf("Season %d", tr.SeasonNumber)
+	}
+	return tr.Title
+}
+
+func (tr ClientJSON) airTime() (TabloDate, bool) {
+	return tr.AirDate, !tr.AirDate.StoredTime.IsZero()
+}
+
+// Objects returns every object bundled in the recording.
+func (tr *Recording) Objects() []TabloObject {
+	var objects []TabloObject
+	candidates := []TabloObject{&tr.RecordedEpisode, &tr.RecordedSeries, &tr.RecordedSeason, &tr.Airing, &tr.RecordedMovie,
+		&tr.RecordedSportEvent, &tr.RecordedSportOrganization}
+	for i := range tr.RecordedSportTeams {
+		candidates = append(candidates, &tr.RecordedSportTeams[i])
+	}
+	candidates = append(candidates, &tr.RecordedManualProgram, &tr.RecordedProgram, &tr.RecordedChannel)
+	for _, object := range candidates {
+		if len(object.GetTabloType()) > 0 {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// Primary returns the object that carries the recorded video: the episode,
+// movie airing, sport event or program. It is nil for an empty recording.
+func (tr *Recording) Primary() TabloObject {
+	for _, object := range []TabloObject{&tr.RecordedEpisode, &tr.Airing, &tr.RecordedSportEvent, &tr.RecordedManualProgram, &tr.RecordedProgram} {
+		if len(object.GetTabloType()) > 0 {
+			return object
+		}
+	}
+	return nil
+}
+
+func (ma *MovieAiring) ObjectID() int {
+	return ma.JSONForClient.ObjectID
+}
+
+func (ma *MovieAiring) DisplayTitle() string {
+	return ma.JSONForClient.displayTitle()
+}
+
+func (ma *MovieAiring) Relationships() Relationships {
+	return ma.JSONForClient.Relationships
+}
+
+func (ma *MovieAiring) Images() []ImageData {
+	return ma.ImageJSON.Images
+}
+
+func (ma *MovieAiring) AirTime() (TabloDate, bool) {
+	return ma.JSONForClient.airTime()
+}
+
+func (ma *MovieAiring) client() ClientJSON {
+	return ma.JSONForClient
+}
+
+func (rm *RecMovie) ObjectID() int {
+	return rm.JSONForClient.ObjectID
+}
+
+func (rm *RecMovie) DisplayTitle() string {
+	return rm.JSONForClient.displayTitle()
+}
+
+func (rm *RecMovie) Relationships() Relationships {
+	return rm.JSONForClient.Relationships
+}
+
+func (rm *RecMovie) Images() []ImageData {
+	return rm.ImageJSON.Images
+}
+
+func (rm *RecMovie) AirTime() (TabloDate, bool) {
+	return rm.JSONForClient.airTime()
+}
+
+func (rm *RecMovie) client() ClientJSON {
+	return rm.JSONForClient
+}
+
+func (rs *RecSeries) ObjectID() int {
+	return rs.JSONForClient.ObjectID
+}
+
+func (rs *RecSeries) DisplayTitle() string {
+	return rs.JSONForClient.displayTitle()
+}
+
+func (rs *RecSeries) Relationships() Relationships {
+	return rs.JSONForClient.Relationships
+}
+
+func (rs *RecSeries) Images() []ImageData {
+	return rs.ImageJSON.Images
+}
+
+func (rs *RecSeries) AirTime() (TabloDate, bool) {
+	return rs.JSONForClient.airTime()
+}
+
+func (rs *RecSeries) client() ClientJSON {
+	return rs.JSONForClient
+}
+
+func (rs *RecSeason) ObjectID() int {
+	return rs.JSONForClient.ObjectID
+}
+
+func (rs *RecSeason) DisplayTitle() string {
+	return rs.JSONForClient.displayTitle()
+}
+
+func (rs *RecSeason) Relationships() Relationships {
+	return rs.JSONForClient.Relationships
+}
+
+func (rs *RecSeason) Images() []ImageData {
+	// The Tablo does not write an imageJson block for seasons.
+	return nil
+}
+
+func (rs *RecSeason) AirTime() (TabloDate, bool) {
+	return rs.JSONForClient.airTime()
+}
+
+func (rs *RecSeason) client() ClientJSON {
+	return rs.JSONForClient
+}
+
+func (re *RecEpisode) ObjectID() int {
+	return re.JSONForClient.ObjectID
+}
+
+func (re *RecEpisode) DisplayTitle() string {
+	return re.JSONForClient.displayTitle()
+}
+
+func (re *RecEpisode) Relationships() Relationships {
+	return re.JSONForClient.Relationships
+}
+
+func (re *RecEpisode) Images() []ImageData {
+	return re.ImageJSON.Images
+}
+
+func (re *RecEpisode) AirTime() (TabloDate, bool) {
+	return re.JSONForClient.airTime()
+}
+
+func (re *RecEpisode) client() ClientJSON {
+	return re.JSONForClient
+}
+
+func (se *RecSportEvent) ObjectID() int {
+	return se.JSONForClient.ObjectID
+}
+
+func (se *RecSportEvent) DisplayTitle() string {
+	return se.JSONForClient.displayTitle()
+}
+
+func (se *RecSportEvent) Relationships() Relationships {
+	return se.JSONForClient.Relationships
+}
+
+func (se *RecSportEvent) Images() []ImageData {
+	return se.ImageJSON.Images
+}
+
+func (se *RecSportEvent) AirTime() (TabloDate, bool) {
+	return se.JSONForClient.ClientJSON().airTime()
+}
+
+func (se *RecSportEvent) client() ClientJSON {
+	return se.JSONForClient.ClientJSON()
+}
+
+func (so *RecSportOrganization) ObjectID() int {
+	return so.JSONForClient.ObjectID
+}
+
+func (so *RecSportOrganization) DisplayTitle() string {
+	return so.JSONForClient.displayTitle()
+}
+
+func (so *RecSportOrganization) Relationships() Relationships {
+	return so.JSONForClient.Relationships
+}
+
+func (so *RecSportOrganization) Images() []ImageData {
+	return so.ImageJSON.Images
+}
+
+func (so *RecSportOrganization) AirTime() (TabloDate, bool) {
+	return so.JSONForClient.airTime()
+}
+
+func (so *RecSportOrganization) client() ClientJSON {
+	return so.JSONForClient
+}
+
+func (st *RecSportTeam) ObjectID() int {
+	return st.JSONForClient.ObjectID
+}
+
+func (st *RecSportTeam) DisplayTitle() string {
+	return st.JSONForClient.displayTitle()
+}
+
+func (st *RecSportTeam) Relationships() Relationships {
+	return st.JSONForClient.Relationships
+}
+
+func (st *RecSportTeam) Images() []ImageData {
+	return st.ImageJSON.Images
+}
+
+func (st *RecSportTeam) AirTime() (TabloDate, bool) {
+	return st.JSONForClient.airTime()
+}
+
+func (st *RecSportTeam) client() ClientJSON {
+	return st.JSONForClient
+}
+
+func (mp *RecManualProgram) ObjectID() int {
+	return mp.JSONForClient.ObjectID
+}
+
+func (mp *RecManualProgram) DisplayTitle() string {
+	return mp.JSONForClient.displayTitle()
+}
+
+func (mp *RecManualProgram) Relationships() Relationships {
+	return mp.JSONForClient.Relationships
+}
+
+func (mp *RecManualProgram) Images() []ImageData {
+	return mp.ImageJSON.Images
+}
+
+func (mp *RecManualProgram) AirTime() (TabloDate, bool) {
+	return mp.JSONForClient.airTime()
+}
+
+func (mp *RecManualProgram) client() ClientJSON {
+	return mp.JSONForClient
+}
+
+func (rp *RecProgram) ObjectID() int {
+	return rp.JSONForClient.ObjectID
+}
+
+func (rp *RecProgram) DisplayTitle() string {
+	return rp.JSONForClient.displayTitle()
+}
+
+func (rp *RecProgram) Relationships() Relationships {
+	return rp.JSONForClient.Relationships
+}
+
+func (rp *RecProgram) Images() []ImageData {
+	return rp.ImageJSON.Images
+}
+
+func (rp *RecProgram) AirTime() (TabloDate, bool) {
+	return rp.JSONForClient.airTime()
+}
+
+func (rp *RecProgram) client() ClientJSON {
+	return rp.JSONForClient
+}
@@ -0,0 +1,60 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestTabloObjectAccessors(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+
+	objects := recording.Objects()
+	if len(objects) != 4 {
+		t.Fatalf("expected 4 objects, got %d", len(objects))
+	}
+	var expected = []struct {
+		objectID int
+		title    string
+		images   int
+		aired    bool
+	}{
+		{343176, "The Virgin Sacrifice", 1, true},
+		{301534, "Midnight, Texas", 1, false},
+		{301535, "Season 1", 0, false},
+		{185238, "KXAS-HD", 0, false},
+	}
+	for i, object := range objects {
+		_, aired := object.AirTime()
+		if object.ObjectID() != expected[i].objectID || object.DisplayTitle() != expected[i].title ||
+			len(object.Images()) != expected[i].images || aired != expected[i].aired {
+			t.Errorf("object %d: got id %d title %q images %d aired %t", i, object.ObjectID(), object.DisplayTitle(), len(object.Images()), aired)
+		}
+	}
+
+	primary := recording.Primary()
+	if primary == nil || primary.GetTabloType() != "recEpisode" || primary.Relationships().RecSeries != 301534 {
+		t.Errorf("unexpected primary object %+v", primary)
+	}
+	airTime, _ := primary.AirTime()
+	if airTime.Format("2006-01-02 15:04") != "2017-09-19 05:00" {
+		t.Errorf("unexpected air time %s", airTime.Format("2006-01-02 15:04"))
+	}
+}
+
+func TestSportEventDisplayTitle(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleSportEventJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	if title := recording.Primary().DisplayTitle(); title != "Dallas Cowboys at New York Giants" {
+		t.Errorf("unexpected sport event title %q", title)
+	}
+	if len(recording.Objects()) != 5 {
+		t.Errorf("expected event, organization, two teams and channel, got %d objects", len(recording.Objects()))
+	}
+}
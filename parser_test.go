@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	tablometadata "github.com/phutson/tablometa"
 )
@@ -49,3 +50,96 @@ func TestParseEpisode(t *testing.T) {
 	}
 
 }
+
+func TestRelationshipsRoundTrip(t *testing.T) {
+	var cases = []struct {
+		relationships tablometadata.Relationships
+		expected      string
+	}{
+		{tablometadata.Relationships{}, `{}`},
+		{tablometadata.Relationships{RecMovie: 117666, RecChannel: 5465}, `{"recMovie":117666,"recChannel":5465}`},
+		{tablometadata.Relationships{RecSeason: 301535, RecSeries: 301534, RecChannel: 185238}, `{"recSeason":301535,"recSeries":301534,"recChannel":185238}`},
+		{tablometadata.Relationships{RecSeries: 301534}, `{"recSeries":301534}`},
+		{tablometadata.Relationships{Genres: []int{108, 335}}, `{"genres":[108,335]}`},
+		{tablometadata.Relationships{RecMovie: 1, RecChannel: 2, Genres: []int{3}}, `{"recMovie":1,"recChannel":2,"genres":[3]}`},
+		{tablometadata.Relationships{RecSeries: 4, RecChannel: 5, Genres: []int{6}}, `{"recSeries":4,"recChannel":5,"genres":[6]}`},
+		{tablometadata.Relationships{RecMovie: 1, RecSeason: 2, RecSeries: 3, RecChannel: 4, Genres: []int{5, 6}}, `{"recMovie":1,"recSeason":2,"recSeries":3,"recChannel":4,"genres":[5,6]}`},
+	}
+
+	for _, testCase := range cases {
+		jsonData, err := json.Marshal(testCase.relationships)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(jsonData) != testCase.expected {
+			t.Errorf("expected %s, got %s", testCase.expected, jsonData)
+		}
+
+		var decoded tablometadata.Relationships
+		if err := json.Unmarshal(jsonData, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.RecMovie != testCase.relationships.RecMovie || decoded.RecSeason != testCase.relationships.RecSeason ||
+			decoded.RecSeries != testCase.relationships.RecSeries || decoded.RecChannel != testCase.relationships.RecChannel ||
+			len(decoded.Genres) != len(testCase.relationships.Genres) {
+			t.Errorf("%s did not round trip: %+v", jsonData, decoded)
+		}
+	}
+}
+
+func TestTabloDateVariants(t *testing.T) {
+	var cases = []struct {
+		encoded  string
+		expected time.Time
+	}{
+		{`"2017-09-19T05:00Z"`, time.Date(2017, 9, 19, 5, 0, 0, 0, time.UTC)},
+		{`"2017-09-19T05:00:12Z"`, time.Date(2017, 9, 19, 5, 0, 12, 0, time.UTC)},
+		{`"2017-09-19T05:00:12.5Z"`, time.Date(2017, 9, 19, 5, 0, 12, 500000000, time.UTC)},
+		{`"2017-09-19T05:00:12.500Z"`, time.Date(2017, 9, 19, 5, 0, 12, 500000000, time.UTC)},
+		{`"2017-09-19T01:00-04:00"`, time.Date(2017, 9, 19, 5, 0, 0, 0, time.UTC)},
+		{`"2017-09-19T01:00:12-0400"`, time.Date(2017, 9, 19, 5, 0, 12, 0, time.UTC)},
+		{`"2017-09-19T05:00:12+00:00"`, time.Date(2017, 9, 19, 5, 0, 12, 0, time.UTC)},
+		{`1505797212`, time.Date(2017, 9, 19, 5, 0, 12, 0, time.UTC)},
+	}
+
+	for _, testCase := range cases {
+		var date tablometadata.TabloDate
+		if err := json.Unmarshal([]byte(testCase.encoded), &date); err != nil {
+			t.Errorf("%s: %v", testCase.encoded, err)
+			continue
+		}
+		if !date.StoredTime.Equal(testCase.expected) {
+			t.Errorf("%s: expected %v, got %v", testCase.encoded, testCase.expected, date.StoredTime)
+		}
+		encoded, err := date.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != testCase.encoded {
+			t.Errorf("expected %s to be written back unchanged, got %s", testCase.encoded, encoded)
+		}
+	}
+
+	for _, encoded := range []string{`"Tuesday night"`, `"2017-09-19"`, `"2017-09-19T05:00"`, `12.5`} {
+		var date tablometadata.TabloDate
+		if err := json.Unmarshal([]byte(encoded), &date); err == nil {
+			t.Errorf("expected %s to be rejected, got %v", encoded, date.StoredTime)
+		}
+	}
+}
+
+func TestTabloDateKeepsLayoutWhenChanged(t *testing.T) {
+	var date tablometadata.TabloDate
+	if err := json.Unmarshal([]byte(`"2017-09-19T01:00:12.25-04:00"`), &date); err != nil {
+		t.Fatal(err)
+	}
+	date.StoredTime = date.StoredTime.Add(90 * time.Minute)
+	if encoded, _ := date.MarshalJSON(); string(encoded) != `"2017-09-19T02:30:12.25-04:00"` {
+		t.Errorf("expected the original layout, got %s", encoded)
+	}
+
+	newDate := tablometadata.TabloDate{StoredTime: time.Date(2017, 9, 19, 1, 0, 0, 0, time.FixedZone("EDT", -4*60*60))}
+	if encoded, _ := newDate.MarshalJSON(); string(encoded) != `"2017-09-19T05:00Z"` {
+		t.Errorf("expected the Tablo layout in UTC, got %s", encoded)
+	}
+}
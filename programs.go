@@ -0,0 +1,51 @@
+package tablometadata
+
+type RecManualProgram struct {
+	JSONForClient ClientJSON    `json:"jsonForClient"`
+	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (mp *RecManualProgram) setLayout(layout *objectLayout) {
+	mp.layout = layout
+}
+
+func (mp RecManualProgram) MarshalJSON() ([]byte, error) {
+	if mp.layout != nil {
+		return encodeWithLayout(mp.layout, mp)
+	}
+	encoder := newObjectEncoder(mp)
+	encoder.Value("JSONForClient", mp.JSONForClient)
+	encoder.Value("ImageJSON", mp.ImageJSON)
+	return encoder.Bytes()
+}
+
+func (mp *RecManualProgram) GetTabloType() string {
+	return mp.JSONForClient.Type
+}
+
+type RecProgram struct {
+	JSONForClient ClientJSON    `json:"jsonForClient"`
+	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (rp *RecProgram) setLayout(layout *objectLayout) {
+	rp.layout = layout
+}
+
+func (rp RecProgram) MarshalJSON() ([]byte, error) {
+	if rp.layout != nil {
+		return encodeWithLayout(rp.layout, rp)
+	}
+	encoder := newObjectEncoder(rp)
+	encoder.Value("JSONForClient", rp.JSONForClient)
+	encoder.Value("ImageJSON", rp.ImageJSON)
+	return encoder.Bytes()
+}
+
+func (rp *RecProgram) GetTabloType() string {
+	return rp.JSONForClient.Type
+}
@@ -0,0 +1,45 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+const (
+	sampleManualProgramJSON = `{"recManualProgram":{"jsonForClient":{"type":"recManualProgram","title":"Manual: Ch 5.1 Sat 8:00 PM","airDate":"2017-09-23T01:00Z","scheduleDuration":5400,"relationships":{"recChannel":185238},"video":{"state":"finished","size":2302616064,"width":1920,"height":1080,"duration":5400.0,"scheduleOffsetStart":0.0,"scheduleOffsetEnd":0.0},"user":{"type":"recordingUserInfo","watched":true,"protected":false,"position":1200.0},"objectID":410001},"imageJson":{"images":[]}}}`
+	sampleProgramJSON       = `{"recProgram":{"jsonForClient":{"type":"recProgram","title":"Macy's Thanksgiving Day Parade","description":"Balloons & floats.","airDate":"2017-11-23T14:00Z","originalAirDate":"2017-11-23","scheduleDuration":10800,"qualifiers":["cc","live"],"relationships":{"recChannel":185238,"genres":[5002]},"video":{"state":"finished","size":7302616064,"width":1920,"height":1080,"duration":10815.0,"scheduleOffsetStart":-15.0,"scheduleOffsetEnd":0.0},"user":{"type":"recordingUserInfo","watched":false,"protected":false,"position":0.0},"objectID":420001},"imageJson":{"images":[{"type":"image","imageID":353599,"imageType":"snapshot","imageStyle":"snapshot"}]}}}`
+)
+
+func TestParsePrograms(t *testing.T) {
+	for _, sampleJSON := range []string{sampleManualProgramJSON, sampleProgramJSON} {
+		var recording tablometadata.Recording
+		if err := json.Unmarshal([]byte(sampleJSON), &recording); err != nil {
+			t.Fatal(err)
+		}
+		if recording.RecordedManualProgram.GetTabloType() == "" && recording.RecordedProgram.GetTabloType() == "" {
+			t.Fatalf("no program decoded from %s", sampleJSON)
+		}
+
+		recording.ClearLayout()
+		jsonData, err := recording.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(jsonData) != sampleJSON {
+			t.Errorf("canonical encoding differs:\n got %s\nwant %s", jsonData, sampleJSON)
+		}
+	}
+}
+
+func TestProgramUserInfo(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleManualProgramJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	manual := recording.RecordedManualProgram.JSONForClient
+	if !manual.User.Watched || manual.User.Position != 1200 || manual.Video.Duration != 5400 || manual.Relationships.RecChannel != 185238 {
+		t.Errorf("unexpected manual program %+v", manual)
+	}
+}
@@ -0,0 +1,229 @@
+package tablometadata
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type ResolutionClass string
+
+const (
+	ResolutionUnknown ResolutionClass = "unknown"
+	ResolutionSD      ResolutionClass = "SD"
+	Resolution720p    ResolutionClass = "720p"
+	Resolution1080i   ResolutionClass = "1080i"
+)
+
+const (
+	DEFAULTOUTLIERSHARE = 0.5
+	DEFAULTMINIMUMPEERS = 3
+)
+
+// Resolution classifies the video by its height the way broadcast video is
+// labelled.
+func (vr VideoInfo) Resolution() ResolutionClass {
+	switch {
+	case vr.Height >= 1000:
+		return Resolution1080i
+	case vr.Height >= 700:
+		return Resolution720p
+	case vr.Height > 0:
+		return ResolutionSD
+	}
+	return ResolutionUnknown
+}
+
+// Bitrate returns the average bitrate of the video in bits per second. It
+// returns false when the size or duration is missing.
+func (vr VideoInfo) Bitrate() (float64, bool) {
+	if vr.Size == 0 || vr.Duration <= 0 {
+		return 0, false
+	}
+	return float64(vr.Size) * 8 / float64(vr.Duration), true
+}
+
+// QualityOptions tunes AnalyzeQuality. A recording is a low-bitrate outlier when
+// its bitrate is below OutlierShare of the median of its peers: recordings of
+// the same resolution on the same channel, or on every channel when the
+// channel has fewer than MinimumPeers of them. Zero values use
+// DEFAULTOUTLIERSHARE and DEFAULTMINIMUMPEERS.
+type QualityOptions struct {
+	OutlierShare float64
+	MinimumPeers int
+}
+
+// VideoQuality is the derived quality of one recording. SeriesID is 0 for
+// recordings that are not episodes.
+type VideoQuality struct {
+	Path       string
+	ObjectID   int
+	Title      string
+	ChannelID  int
+	SeriesID   int
+	Resolution ResolutionClass
+	Duration   time.Duration
+	Bitrate    float64
+}
+
+// QualitySummary aggregates the recordings of one channel or series. Bitrates
+// are in bits per second.
+type QualitySummary struct {
+	ID            int
+	Title         string
+	Recordings    int
+	MeanBitrate   float64
+	MedianBitrate float64
+	MinBitrate    float64
+	MaxBitrate    float64
+	Resolutions   map[ResolutionClass]int
+}
+
+// LowBitrateOutlier is a recording whose bitrate is abnormally low next to the
+// median of its peers.
+type LowBitrateOutlier struct {
+	VideoQuality
+	PeerMedian float64
+	Peers      int
+}
+
+func (lo LowBitrateOutlier) String() string {
+	return fmt.Sprintf("%d %q on channel %d: %.2f Mbit/s against a median of %.2f Mbit/s (%s)",
+		lo.ObjectID, lo.Title, lo.ChannelID, lo.Bitrate/1e6, lo.PeerMedian/1e6, lo.Path)
+}
+
+// QualityReport lists every recording with a measurable bitrate, summaries
+// per channel and per series ordered by ID, and the low-bitrate outliers
+// ordered by how far below their peers they are.
+type QualityReport struct {
+	Recordings []VideoQuality
+	ByChannel  []QualitySummary
+	BySeries   []QualitySummary
+	Outliers   []LowBitrateOutlier
+}
+
+// AnalyzeQuality runs the package level AnalyzeQuality over every recording in
+// the library.
+func (tl *Library) AnalyzeQuality(options QualityOptions) QualityReport {
+	return AnalyzeQuality(tl.Recordings, options)
+}
+
+// AnalyzeQuality derives the bitrate and resolution class of every recording,
+// summarizes them per channel and per series and finds recordings with an
+// abnormally low bitrate, which usually points at a weak signal.
+func AnalyzeQuality(recordings []LibraryRecording, options QualityOptions) QualityReport {
+	if options.OutlierShare <= 0 {
+		options.OutlierShare = DEFAULTOUTLIERSHARE
+	}
+	if options.MinimumPeers < 1 {
+		options.MinimumPeers = DEFAULTMINIMUMPEERS
+	}
+
+	var report QualityReport
+	seriesTitles := make(map[int]string)
+	for i := range recordings {
+		recording := &recordings[i].Recording
+		primary := recording.Primary()
+		if primary == nil {
+			continue
+		}
+		client := clientOf(primary)
+		bitrate, wasMeasured := client.Video.Bitrate()
+		if !wasMeasured {
+			continue
+		}
+		quality := VideoQuality{Path: recordings[i].Path, ObjectID: primary.ObjectID(), Title: primary.DisplayTitle(),
+			ChannelID: client.Relationships.RecChannel, Resolution: client.Video.Resolution(),
+			Duration: secondsDuration(client.Video.Duration), Bitrate: bitrate}
+		if client.Type == "recEpisode" {
+			quality.SeriesID = client.Relationships.RecSeries
+			if series := recording.RecordedSeries.JSONForClient; series.ObjectID == quality.SeriesID && len(series.Title) > 0 {
+				seriesTitles[quality.SeriesID] = series.Title
+			}
+		}
+		report.Recordings = append(report.Recordings, quality)
+	}
+
+	report.ByChannel = summarizeQuality(report.Recordings, func(quality VideoQuality) int { return quality.ChannelID }, nil)
+	report.BySeries = summarizeQuality(report.Recordings, func(quality VideoQuality) int { return quality.SeriesID }, seriesTitles)
+	report.Outliers = findLowBitrateOutliers(report.Recordings, options)
+	return report
+}
+
+func summarizeQuality(qualities []VideoQuality, groupID func(quality VideoQuality) int, titles map[int]string) []QualitySummary {
+	groups := make(map[int][]VideoQuality)
+	for _, quality := range qualities {
+		if id := groupID(quality); id != 0 {
+			groups[id] = append(groups[id], quality)
+		}
+	}
+
+	summaries := make([]QualitySummary, 0, len(groups))
+	for id, group := range groups {
+		summary := QualitySummary{ID: id, Title: titles[id], Recordings: len(group), Resolutions: make(map[ResolutionClass]int)}
+		bitrates := make([]float64, len(group))
+		for i, quality := range group {
+			bitrates[i] = quality.Bitrate
+			summary.MeanBitrate += quality.Bitrate
+			summary.Resolutions[quality.Resolution]++
+		}
+		sort.Float64s(bitrates)
+		summary.MeanBitrate /= float64(len(group))
+		summary.MedianBitrate = medianOfSorted(bitrates)
+		summary.MinBitrate = bitrates[0]
+		summary.MaxBitrate = bitrates[len(bitrates)-1]
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries
+}
+
+type peerGroup struct {
+	channelID  int
+	resolution ResolutionClass
+}
+
+func findLowBitrateOutliers(qualities []VideoQuality, options QualityOptions) []LowBitrateOutlier {
+	channelBitrates := make(map[peerGroup][]float64)
+	resolutionBitrates := make(map[ResolutionClass][]float64)
+	for _, quality := range qualities {
+		group := peerGroup{quality.ChannelID, quality.Resolution}
+		channelBitrates[group] = append(channelBitrates[group], quality.Bitrate)
+		resolutionBitrates[quality.Resolution] = append(resolutionBitrates[quality.Resolution], quality.Bitrate)
+	}
+	for _, bitrates := range channelBitrates {
+		sort.Float64s(bitrates)
+	}
+	for _, bitrates := range resolutionBitrates {
+		sort.Float64s(bitrates)
+	}
+
+	var outliers []LowBitrateOutlier
+	for _, quality := range qualities {
+		peers := channelBitrates[peerGroup{quality.ChannelID, quality.Resolution}]
+		if len(peers) < options.MinimumPeers {
+			peers = resolutionBitrates[quality.Resolution]
+		}
+		if len(peers) < options.MinimumPeers {
+			continue
+		}
+		median := medianOfSorted(peers)
+		if quality.Bitrate < options.OutlierShare*median {
+			outliers = append(outliers, LowBitrateOutlier{VideoQuality: quality, PeerMedian: median, Peers: len(peers)})
+		}
+	}
+	sort.SliceStable(outliers, func(i, j int) bool {
+		return outliers[i].Bitrate/outliers[i].PeerMedian < outliers[j].Bitrate/outliers[j].PeerMedian
+	})
+	return outliers
+}
+
+func medianOfSorted(values []float64) float64 {
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
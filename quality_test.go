@@ -0,0 +1,74 @@
+package tablometadata_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func withSize(size uint64) func(recording *tablometadata.Recording) {
+	return func(recording *tablometadata.Recording) {
+		recording.RecordedEpisode.JSONForClient.Video.Size = size
+	}
+}
+
+func TestVideoInfoBitrateAndResolution(t *testing.T) {
+	video := tablometadata.VideoInfo{Size: 5302616064, Height: 1080, Duration: 5417.0}
+	bitrate, wasMeasured := video.Bitrate()
+	if !wasMeasured || math.Abs(bitrate-5302616064*8/5417.0) > 1 {
+		t.Errorf("unexpected bitrate %f", bitrate)
+	}
+	if _, wasMeasured := (tablometadata.VideoInfo{Size: 1}).Bitrate(); wasMeasured {
+		t.Error("expected no bitrate without a duration")
+	}
+
+	for height, expected := range map[int]tablometadata.ResolutionClass{0: tablometadata.ResolutionUnknown, 480: tablometadata.ResolutionSD,
+		720: tablometadata.Resolution720p, 1080: tablometadata.Resolution1080i} {
+		if resolution := (tablometadata.VideoInfo{Height: height}).Resolution(); resolution != expected {
+			t.Errorf("height %d: expected %s, got %s", height, expected, resolution)
+		}
+	}
+}
+
+func TestAnalyzeQuality(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z", withSize(5302616064)),
+		episodeAiredOn(t, 2, "2017-09-19T05:00Z", withSize(5202616064)),
+		episodeAiredOn(t, 3, "2017-09-19T05:00Z", withSize(5402616064)),
+		episodeAiredOn(t, 4, "2017-09-19T05:00Z", withSize(1302616064)),
+		episodeAiredOn(t, 5, "2017-09-19T05:00Z", withSize(0)),
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+	}
+	report := tablometadata.AnalyzeQuality(recordings, tablometadata.QualityOptions{})
+
+	if len(report.Recordings) != 5 {
+		t.Fatalf("expected five measured recordings, got %d", len(report.Recordings))
+	}
+	if len(report.ByChannel) != 2 || report.ByChannel[0].ID != 5465 || report.ByChannel[1].ID != 185238 {
+		t.Fatalf("unexpected channel summaries %+v", report.ByChannel)
+	}
+	channel := report.ByChannel[1]
+	if channel.Recordings != 4 || channel.Resolutions[tablometadata.Resolution1080i] != 4 || channel.MinBitrate >= channel.MedianBitrate {
+		t.Errorf("unexpected channel summary %+v", channel)
+	}
+	if report.ByChannel[0].Resolutions[tablometadata.Resolution720p] != 1 {
+		t.Errorf("expected the movie to be 720p, got %+v", report.ByChannel[0])
+	}
+	if len(report.BySeries) != 1 || report.BySeries[0].ID != 301534 || report.BySeries[0].Title != "Midnight, Texas" || report.BySeries[0].Recordings != 4 {
+		t.Errorf("unexpected series summaries %+v", report.BySeries)
+	}
+
+	if len(report.Outliers) != 1 || report.Outliers[0].ObjectID != 4 || report.Outliers[0].Peers != 4 {
+		t.Fatalf("unexpected outliers %+v", report.Outliers)
+	}
+	if !strings.Contains(report.Outliers[0].String(), "rec/4/meta.txt") {
+		t.Errorf("unexpected description %s", report.Outliers[0])
+	}
+
+	strict := tablometadata.AnalyzeQuality(recordings, tablometadata.QualityOptions{MinimumPeers: 5})
+	if len(strict.Outliers) != 0 {
+		t.Errorf("expected too few peers for outliers, got %+v", strict.Outliers)
+	}
+}
@@ -0,0 +1,118 @@
+package tablometadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ObjectDecoder decodes a Tablo object ({"jsonForClient":...}) whose
+// jsonForClient.type it was registered for.
+type ObjectDecoder func(data []byte) (TabloType, error)
+
+var ErrMissingObjectType = errors.New("jsonForClient.type not found")
+
+type UnknownObjectTypeError struct {
+	Type string
+}
+
+func (ue UnknownObjectTypeError) Error() string {
+	return fmt.Sprintf("no decoder registered for object type %q", ue.Type)
+}
+
+var (
+	objectDecodersMutex sync.RWMutex
+	objectDecoders      = map[string]ObjectDecoder{
+		"recMovieAiring": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &MovieAiring{})
+		},
+		"recMovie": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecMovie{})
+		},
+		"recEpisode": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecEpisode{})
+		},
+		"recSeries": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecSeries{})
+		},
+		"recSeason": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecSeason{})
+		},
+		"recSportEvent": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecSportEvent{})
+		},
+		"recSportOrganization": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecSportOrganization{})
+		},
+		"recSportTeam": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecSportTeam{})
+		},
+		"recManualProgram": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecManualProgram{})
+		},
+		"recProgram": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecProgram{})
+		},
+		"recChannel": func(data []byte) (TabloType, error) {
+			return decodeInto(data, &RecChannel{})
+		},
+	}
+)
+
+// decodeInto decodes data into object, returning nil rather than a partly
+// decoded object on error.
+func decodeInto(data []byte, object TabloType) (TabloType, error) {
+	if err := json.Unmarshal(data, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// RegisterObjectDecoder makes DecodeObject use decoder for objects of
+// tabloType, replacing any decoder already registered for it.
+func RegisterObjectDecoder(tabloType string, decoder ObjectDecoder) error {
+	if len(tabloType) < 1 {
+		return errors.New("object type must not be empty")
+	}
+	if decoder == nil {
+		return errors.New("decoder must not be nil")
+	}
+	objectDecodersMutex.Lock()
+	defer objectDecodersMutex.Unlock()
+	objectDecoders[tabloType] = decoder
+	return nil
+}
+
+// UnregisterObjectDecoder removes the decoder registered for tabloType, so
+// DecodeObject reports objects of that type as UnknownObjectTypeError again.
+func UnregisterObjectDecoder(tabloType string) {
+	objectDecodersMutex.Lock()
+	defer objectDecodersMutex.Unlock()
+	delete(objectDecoders, tabloType)
+}
+
+// DecodeObject reads jsonForClient.type from a single Tablo object and decodes
+// it into the matching concrete type, such as *RecEpisode or *MovieAiring.
+func DecodeObject(data []byte) (TabloType, error) {
+	var envelope struct {
+		JSONForClient struct {
+			Type string `json:"type"`
+		} `json:"jsonForClient"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	tabloType := envelope.JSONForClient.Type
+	if len(tabloType) < 1 {
+		return nil, ErrMissingObjectType
+	}
+
+	objectDecodersMutex.RLock()
+	decoder, wasFound := objectDecoders[tabloType]
+	objectDecodersMutex.RUnlock()
+	if !wasFound {
+		return nil, UnknownObjectTypeError{Type: tabloType}
+	}
+	return decoder(data)
+}
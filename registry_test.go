@@ -0,0 +1,99 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestDecodeObject(t *testing.T) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &members); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := tablometadata.DecodeObject(members["recEpisode"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	episode, isEpisode := object.(*tablometadata.RecEpisode)
+	if !isEpisode || episode.JSONForClient.Title != "The Virgin Sacrifice" {
+		t.Fatalf("expected *RecEpisode, got %T %+v", object, object)
+	}
+
+	object, err = tablometadata.DecodeObject(members["recSeason"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, isSeason := object.(*tablometadata.RecSeason); !isSeason {
+		t.Fatalf("expected *RecSeason, got %T", object)
+	}
+}
+
+func TestDecodeObjectErrors(t *testing.T) {
+	if _, err := tablometadata.DecodeObject([]byte(`{"jsonForClient":{"title":"untyped"}}`)); err != tablometadata.ErrMissingObjectType {
+		t.Errorf("expected ErrMissingObjectType, got %v", err)
+	}
+
+	_, err := tablometadata.DecodeObject([]byte(`{"jsonForClient":{"type":"recUnheardOf"}}`))
+	var unknownType tablometadata.UnknownObjectTypeError
+	if !errors.As(err, &unknownType) || unknownType.Type != "recUnheardOf" {
+		t.Errorf("expected UnknownObjectTypeError, got %v", err)
+	}
+
+	object, err := tablometadata.DecodeObject([]byte(`{"jsonForClient":{"type":"recEpisode","episodeNumber":"ten"}}`))
+	if err == nil || object != nil {
+		t.Errorf("expected a decode error and no object, got %T %v", object, err)
+	}
+}
+
+type recChannel struct {
+	JSONForClient struct {
+		Type    string `json:"type"`
+		Channel struct {
+			CallSign string `json:"callSign"`
+		} `json:"channel"`
+	} `json:"jsonForClient"`
+}
+
+func (rc *recChannel) GetTabloType() string {
+	return rc.JSONForClient.Type
+}
+
+func TestRegisterObjectDecoder(t *testing.T) {
+	err := tablometadata.RegisterObjectDecoder("recChannel", func(data []byte) (tablometadata.TabloType, error) {
+		var channel recChannel
+		if err := json.Unmarshal(data, &channel); err != nil {
+			return nil, err
+		}
+		return &channel, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		tablometadata.UnregisterObjectDecoder("recChannel")
+	})
+
+	object, err := tablometadata.DecodeObject([]byte(`{"jsonForClient":{"type":"recChannel","channel":{"callSign":"KXAS-HD"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel, isChannel := object.(*recChannel)
+	if !isChannel || channel.JSONForClient.Channel.CallSign != "KXAS-HD" {
+		t.Fatalf("expected the registered decoder to be used, got %T %+v", object, object)
+	}
+
+	if err := tablometadata.RegisterObjectDecoder("", nil); err == nil {
+		t.Error("expected an error registering an empty type")
+	}
+
+	tablometadata.UnregisterObjectDecoder("recChannel")
+	_, err = tablometadata.DecodeObject([]byte(`{"jsonForClient":{"type":"recChannel"}}`))
+	var unknownType tablometadata.UnknownObjectTypeError
+	if !errors.As(err, &unknownType) {
+		t.Errorf("expected UnknownObjectTypeError after unregistering, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package tablometadata
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// RetentionRule selects recordings a cleanup policy would delete. Validate
+// rejects settings that would propose far more than intended, such as a zero
+// value rule; PlanRetention refuses to run with an invalid rule. Propose
+// returns indexes into recordings; it does not need to skip protected
+// recordings, PlanRetention never proposes those.
+type RetentionRule interface {
+	Name() string
+	Validate() error
+	Propose(recordings []LibraryRecording, now time.Time) []int
+}
+
+// KeepNewestEpisodes proposes every episode of a series except the Count most
+// recently aired ones. A Count below one is invalid and proposes nothing, so a
+// zero value rule can never plan to delete every episode.
+type KeepNewestEpisodes struct {
+	Count int
+}
+
+func (kn KeepNewestEpisodes) Validate() error {
+	vd := &validator{}
+	vd.check(kn.Count >= 1, "count", "must be at least 1, got %d", kn.Count)
+	return vd.err()
+}
+
+func (kn KeepNewestEpisodes) Name() string {
+	return fmt.Sprintf("keep the newest %d episodes per series", kn.Count)
+}
+
+func (kn KeepNewestEpisodes) Propose(recordings []LibraryRecording, now time.Time) []int {
+	if kn.Validate() != nil {
+		return nil
+	}
+	episodesBySeries := make(map[int][]int)
+	for i := range recordings {
+		episode := recordings[i].Recording.RecordedEpisode.JSONForClient
+		if episode.Type == "recEpisode" && episode.Relationships.RecSeries != 0 {
+			episodesBySeries[episode.Relationships.RecSeries] = append(episodesBySeries[episode.Relationships.RecSeries], i)
+		}
+	}
+
+	var proposed []int
+	for _, episodes := range episodesBySeries {
+		sort.SliceStable(episodes, func(i, j int) bool {
+			airDate := recordings[episodes[i]].Recording.RecordedEpisode.JSONForClient.AirDate.StoredTime
+			return airDate.After(recordings[episodes[j]].Recording.RecordedEpisode.JSONForClient.AirDate.StoredTime)
+		})
+		if len(episodes) > kn.Count {
+			proposed = append(proposed, episodes[kn.Count:]...)
+		}
+	}
+	return proposed
+}
+
+// DeleteWatchedOlderThan proposes watched recordings that aired more than Age
+// before now. An Age that is not positive is invalid and proposes nothing,
+// rather than every watched recording.
+type DeleteWatchedOlderThan struct {
+	Age time.Duration
+}
+
+func (dw DeleteWatchedOlderThan) Validate() error {
+	vd := &validator{}
+	vd.check(dw.Age > 0, "age", "must be positive, got %v", dw.Age)
+	return vd.err()
+}
+
+func (dw DeleteWatchedOlderThan) Name() string {
+	return fmt.Sprintf("delete watched recordings older than %v", dw.Age)
+}
+
+func (dw DeleteWatchedOlderThan) Propose(recordings []LibraryRecording, now time.Time) []int {
+	if dw.Validate() != nil {
+		return nil
+	}
+	cutoff := now.Add(-dw.Age)
+	var proposed []int
+	for i := range recordings {
+		primary := recordings[i].Recording.Primary()
+		if primary == nil {
+			continue
+		}
+		client := clientOf(primary)
+		if client.User.Watched && !client.AirDate.StoredTime.IsZero() && client.AirDate.StoredTime.Before(cutoff) {
+			proposed = append(proposed, i)
+		}
+	}
+	return proposed
+}
+
+// KeepMoviesRatedAbove proposes movies whose QualityRating is not above
+// Rating. Movies without a rating are kept. A Rating that is not a positive
+// number is invalid and proposes nothing.
+type KeepMoviesRatedAbove struct {
+	Rating float32
+}
+
+func (km KeepMoviesRatedAbove) Validate() error {
+	vd := &validator{}
+	vd.check(km.Rating > 0 && !math.IsInf(float64(km.Rating), 0), "rating", "must be a positive number, got %.3f", km.Rating)
+	return vd.err()
+}
+
+func (km KeepMoviesRatedAbove) Name() string {
+	return fmt.Sprintf("keep movies rated above %.3f", km.Rating)
+}
+
+func (km KeepMoviesRatedAbove) Propose(recordings []LibraryRecording, now time.Time) []int {
+	if km.Validate() != nil {
+		return nil
+	}
+	var proposed []int
+	for i := range recordings {
+		movie := recordings[i].Recording.RecordedMovie.JSONForClient
+		if len(recordings[i].Recording.Airing.JSONForClient.Type) > 0 && movie.QualityRating > 0 && movie.QualityRating <= km.Rating {
+			proposed = append(proposed, i)
+		}
+	}
+	return proposed
+}
+
+// RetentionProposal is one recording a plan would delete. Rule is the first
+// rule that matched it and Rules every rule that did.
+type RetentionProposal struct {
+	Path     string
+	ObjectID int
+	Title    string
+	Bytes    uint64
+	Rule     string
+	Rules    []string
+}
+
+func (rp RetentionProposal) String() string {
+	return fmt.Sprintf("%s: %q, %d bytes (%s)", rp.Path, rp.Title, rp.Bytes, rp.Rule)
+}
+
+// RetentionPlan is a dry run of a set of retention rules. Nothing is deleted;
+// Proposals lists what the rules would remove, ordered by path, and Protected
+// lists the recordings a rule matched but that are protected and so are kept.
+type RetentionPlan struct {
+	Proposals      []RetentionProposal
+	Protected      []RetentionProposal
+	ReclaimedBytes uint64
+}
+
+// PlanRetention runs the package level PlanRetention over every recording in
+// the library.
+func (tl *Library) PlanRetention(rules []RetentionRule, now time.Time) (RetentionPlan, error) {
+	return PlanRetention(tl.Recordings, rules, now)
+}
+
+// PlanRetention evaluates rules against recordings and returns the deletion
+// plan. A recording with UserInfo.Protected set is never proposed, whatever
+// the rules return. When any rule fails Validate no rule is applied, and the
+// returned ValidationErrors lists every problem, located as rules[i].field.
+func PlanRetention(recordings []LibraryRecording, rules []RetentionRule, now time.Time) (RetentionPlan, error) {
+	if err := validateRetentionRules(rules); err != nil {
+		return RetentionPlan{}, err
+	}
+
+	matches := make(map[int]*RetentionProposal)
+	for _, rule := range rules {
+		for _, index := range rule.Propose(recordings, now) {
+			if index < 0 || index >= len(recordings) {
+				continue
+			}
+			proposal, wasFound := matches[index]
+			if !wasFound {
+				proposal = newRetentionProposal(recordings[index], rule.Name())
+				if proposal == nil {
+					continue
+				}
+				matches[index] = proposal
+			}
+			if !containsString(proposal.Rules, rule.Name()) {
+				proposal.Rules = append(proposal.Rules, rule.Name())
+			}
+		}
+	}
+
+	var plan RetentionPlan
+	for index, proposal := range matches {
+		if clientOf(recordings[index].Recording.Primary()).User.Protected {
+			plan.Protected = append(plan.Protected, *proposal)
+			continue
+		}
+		plan.Proposals = append(plan.Proposals, *proposal)
+		plan.ReclaimedBytes += proposal.Bytes
+	}
+	sortRetentionProposals(plan.Proposals)
+	sortRetentionProposals(plan.Protected)
+	return plan, nil
+}
+
+func validateRetentionRules(rules []RetentionRule) error {
+	vd := &validator{}
+	for i, rule := range rules {
+		prefix := fmt.Sprintf("rules[%d]", i)
+		err := rule.Validate()
+		var validationErrors ValidationErrors
+		switch {
+		case err == nil:
+		case errors.As(err, &validationErrors):
+			for _, validationError := range validationErrors {
+				vd.check(false, joinJSONPath(prefix, validationError.Field), "%s", validationError.Message)
+			}
+		default:
+			vd.check(false, prefix, "%v", err)
+		}
+	}
+	return vd.err()
+}
+
+func newRetentionProposal(libraryRecording LibraryRecording, rule string) *RetentionProposal {
+	primary := libraryRecording.Recording.Primary()
+	if primary == nil {
+		return nil
+	}
+	return &RetentionProposal{Path: libraryRecording.Path, ObjectID: primary.ObjectID(), Title: primary.DisplayTitle(),
+		Bytes: clientOf(primary).Video.Size, Rule: rule}
+}
+
+func sortRetentionProposals(proposals []RetentionProposal) {
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].Path < proposals[j].Path
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package tablometadata_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestPlanRetentionKeepNewestEpisodes(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-05T05:00Z"),
+		episodeAiredOn(t, 2, "2017-09-19T05:00Z"),
+		episodeAiredOn(t, 3, "2017-08-29T05:00Z", func(recording *tablometadata.Recording) {
+			recording.RecordedEpisode.JSONForClient.User.Protected = true
+		}),
+		episodeAiredOn(t, 4, "2017-09-12T05:00Z"),
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+	}
+	plan, err := tablometadata.PlanRetention(recordings, []tablometadata.RetentionRule{tablometadata.KeepNewestEpisodes{Count: 2}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Proposals) != 1 || plan.Proposals[0].ObjectID != 1 || plan.Proposals[0].Rule != "keep the newest 2 episodes per series" {
+		t.Fatalf("unexpected proposals %v", plan.Proposals)
+	}
+	if plan.ReclaimedBytes != 5302616064 {
+		t.Errorf("unexpected reclaimed bytes %d", plan.ReclaimedBytes)
+	}
+	if len(plan.Protected) != 1 || plan.Protected[0].ObjectID != 3 {
+		t.Errorf("expected the protected episode to be kept, got %v", plan.Protected)
+	}
+}
+
+func TestRetentionRulesRejectInvalidSettings(t *testing.T) {
+	watched := func(recording *tablometadata.Recording) {
+		recording.RecordedEpisode.JSONForClient.User.Watched = true
+	}
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-05T05:00Z", watched),
+		episodeAiredOn(t, 2, "2017-09-19T05:00Z", watched),
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+	}
+	now := time.Date(2017, 10, 10, 0, 0, 0, 0, time.UTC)
+	invalid := []tablometadata.RetentionRule{
+		tablometadata.KeepNewestEpisodes{},
+		tablometadata.KeepNewestEpisodes{Count: -1},
+		tablometadata.DeleteWatchedOlderThan{},
+		tablometadata.DeleteWatchedOlderThan{Age: -time.Hour},
+		tablometadata.KeepMoviesRatedAbove{},
+		tablometadata.KeepMoviesRatedAbove{Rating: float32(math.Inf(1))},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", rule.Name())
+		}
+		if proposed := rule.Propose(recordings, now); len(proposed) != 0 {
+			t.Errorf("%s: expected no proposals, got %v", rule.Name(), proposed)
+		}
+	}
+
+	rules := []tablometadata.RetentionRule{tablometadata.KeepNewestEpisodes{Count: 1}, tablometadata.DeleteWatchedOlderThan{}}
+	plan, err := tablometadata.PlanRetention(recordings, rules, now)
+	var validationErrors tablometadata.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) != 1 || validationErrors[0].Field != "rules[1].age" {
+		t.Fatalf("expected the zero age to be reported, got %v", err)
+	}
+	if len(plan.Proposals) != 0 || len(plan.Protected) != 0 {
+		t.Errorf("expected no rule to be applied, got %+v", plan)
+	}
+
+	valid := []tablometadata.RetentionRule{
+		tablometadata.KeepNewestEpisodes{Count: 1},
+		tablometadata.DeleteWatchedOlderThan{Age: time.Hour},
+		tablometadata.KeepMoviesRatedAbove{Rating: 0.5},
+	}
+	for _, rule := range valid {
+		if err := rule.Validate(); err != nil {
+			t.Errorf("%s: unexpected validation error %v", rule.Name(), err)
+		}
+	}
+}
+
+func TestPlanRetentionCombinesRules(t *testing.T) {
+	watched := func(recording *tablometadata.Recording) {
+		recording.RecordedEpisode.JSONForClient.User.Watched = true
+	}
+	watchedMovie := decodeLibraryRecording(t, "movie", sampleMovieJSON)
+	watchedMovie.Recording.Airing.JSONForClient.User.Watched = true
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-05T05:00Z", watched),
+		episodeAiredOn(t, 2, "2017-09-19T05:00Z", watched),
+		watchedMovie,
+	}
+	rules := []tablometadata.RetentionRule{
+		tablometadata.DeleteWatchedOlderThan{Age: 30 * 24 * time.Hour},
+		tablometadata.KeepMoviesRatedAbove{Rating: 0.5},
+	}
+	now := time.Date(2017, 10, 10, 0, 0, 0, 0, time.UTC)
+	plan, err := tablometadata.PlanRetention(recordings, rules, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Proposals) != 2 || plan.Proposals[0].Path != "movie" || plan.Proposals[1].ObjectID != 1 {
+		t.Fatalf("unexpected proposals %v", plan.Proposals)
+	}
+	if movie := plan.Proposals[0]; movie.Rule != rules[0].Name() || len(movie.Rules) != 2 || movie.Rules[1] != rules[1].Name() {
+		t.Errorf("expected both rules to be listed, got %+v", movie)
+	}
+	if plan.ReclaimedBytes != 5302616064+3415293952 {
+		t.Errorf("unexpected reclaimed bytes %d", plan.ReclaimedBytes)
+	}
+}
+
+type everythingRule struct{}
+
+func (everythingRule) Name() string {
+	return "everything"
+}
+
+func (everythingRule) Validate() error {
+	return nil
+}
+
+func (everythingRule) Propose(recordings []tablometadata.LibraryRecording, now time.Time) []int {
+	proposed := make([]int, len(recordings))
+	for i := range recordings {
+		proposed[i] = i
+	}
+	return proposed
+}
+
+func TestPlanRetentionNeverProposesProtected(t *testing.T) {
+	protected := decodeLibraryRecording(t, "movie", sampleMovieJSON)
+	protected.Recording.Airing.JSONForClient.User.Protected = true
+	recordings := []tablometadata.LibraryRecording{protected}
+	plan, err := tablometadata.PlanRetention(recordings, []tablometadata.RetentionRule{everythingRule{}}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Proposals) != 0 || plan.ReclaimedBytes != 0 || len(plan.Protected) != 1 {
+		t.Errorf("expected the protected movie to be kept, got %+v", plan)
+	}
+}
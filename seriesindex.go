@@ -0,0 +1,295 @@
+package tablometadata
+
+import (
+	"sort"
+	"strings"
+)
+
+// EpisodeNode is one recorded episode in a SeriesIndex.
+type EpisodeNode struct {
+	Episode RecEpisode
+	Paths   []string
+}
+
+// SeasonNode is a season and its episodes in broadcast order. Season is the
+// first copy of the recSeason read; it is empty when no meta file bundles it.
+type SeasonNode struct {
+	SeasonID     int
+	SeasonNumber int
+	Season       RecSeason
+	Paths        []string
+	Episodes     []*EpisodeNode
+}
+
+// SeriesNode is a series and its seasons in broadcast order. Series is the
+// first copy of the recSeries read; it is empty when no meta file bundles it.
+type SeriesNode struct {
+	SeriesID int
+	Series   RecSeries
+	Paths    []string
+	Seasons  []*SeasonNode
+}
+
+// Title returns the series title, or "" when the series was not bundled.
+func (sn *SeriesNode) Title() string {
+	return sn.Series.JSONForClient.Title
+}
+
+// Season returns the season numbered seasonNumber.
+func (sn *SeriesNode) Season(seasonNumber int) (*SeasonNode, bool) {
+	for _, season := range sn.Seasons {
+		if season.SeasonNumber == seasonNumber {
+			return season, true
+		}
+	}
+	return nil, false
+}
+
+// Episodes returns every episode of the series in broadcast order.
+func (sn *SeriesNode) Episodes() []*EpisodeNode {
+	var episodes []*EpisodeNode
+	for _, season := range sn.Seasons {
+		episodes = append(episodes, season.Episodes...)
+	}
+	return episodes
+}
+
+// SeriesIndex is the series, season and episode tree of a library. Every
+// episode meta file repeats its recSeries and recSeason; the index keeps one
+// node per object and reports copies that disagree in Conflicts.
+type SeriesIndex struct {
+	Conflicts []IntegrityIssue
+
+	series   []*SeriesNode
+	seriesBy map[int]*SeriesNode
+	seasonBy map[seasonKey]*SeasonNode
+	episodes map[int]*EpisodeNode
+}
+
+// seasonKey identifies a season node. Season IDs are only looked up within
+// their series, so episodes of two series that link the same season ID do not
+// end up in one node.
+type seasonKey struct {
+	seriesID int
+	seasonID int
+}
+
+// SeriesIndex builds the package level NewSeriesIndex over every recording in
+// the library.
+func (tl *Library) SeriesIndex() *SeriesIndex {
+	return NewSeriesIndex(tl.Recordings)
+}
+
+// NewSeriesIndex builds the tree from the episodes in recordings. Seasons are
+// ordered by number with specials (season 0) last, and episodes by episode
+// number, with unnumbered episodes after the numbered ones, then by air date.
+// A season ID linked by episodes of more than one series is reported in
+// Conflicts and gets a season node in each series.
+func NewSeriesIndex(recordings []LibraryRecording) *SeriesIndex {
+	index := &SeriesIndex{seriesBy: make(map[int]*SeriesNode), seasonBy: make(map[seasonKey]*SeasonNode), episodes: make(map[int]*EpisodeNode)}
+	seasonSeries := make(map[int]map[int]bool)
+	seasonPaths := make(map[int][]string)
+	occurrences := make(map[int][]objectOccurrence)
+	var objectIDs []int
+	addOccurrence := func(path string, client ClientJSON) {
+		if _, wasFound := occurrences[client.ObjectID]; !wasFound {
+			objectIDs = append(objectIDs, client.ObjectID)
+		}
+		occurrences[client.ObjectID] = append(occurrences[client.ObjectID], objectOccurrence{path: path, client: client})
+	}
+
+	for i := range recordings {
+		path := recordings[i].Path
+		recording := &recordings[i].Recording
+		episode := recording.RecordedEpisode
+		if episode.JSONForClient.Type != "recEpisode" || episode.JSONForClient.Relationships.RecSeries == 0 {
+			continue
+		}
+
+		seriesID := episode.JSONForClient.Relationships.RecSeries
+		series, wasFound := index.seriesBy[seriesID]
+		if !wasFound {
+			series = &SeriesNode{SeriesID: seriesID}
+			index.seriesBy[seriesID] = series
+			index.series = append(index.series, series)
+		}
+		if recordedSeries := recording.RecordedSeries; recordedSeries.JSONForClient.ObjectID == seriesID {
+			if len(series.Paths) == 0 {
+				series.Series = recordedSeries
+			}
+			series.Paths = appendPath(series.Paths, path)
+			addOccurrence(path, recordedSeries.JSONForClient)
+		}
+
+		season := index.season(series, episode.JSONForClient, recording.RecordedSeason)
+		if season.SeasonID != 0 {
+			if seasonSeries[season.SeasonID] == nil {
+				seasonSeries[season.SeasonID] = make(map[int]bool)
+			}
+			seasonSeries[season.SeasonID][seriesID] = true
+			seasonPaths[season.SeasonID] = appendPath(seasonPaths[season.SeasonID], path)
+		}
+		if recordedSeason := recording.RecordedSeason; recordedSeason.JSONForClient.ObjectID == season.SeasonID && season.SeasonID != 0 {
+			if len(season.Paths) == 0 {
+				season.Season = recordedSeason
+			}
+			season.Paths = appendPath(season.Paths, path)
+			addOccurrence(path, recordedSeason.JSONForClient)
+		}
+
+		episodeNode, wasFound := index.episodes[episode.JSONForClient.ObjectID]
+		if !wasFound {
+			episodeNode = &EpisodeNode{Episode: episode}
+			index.episodes[episode.JSONForClient.ObjectID] = episodeNode
+			season.Episodes = append(season.Episodes, episodeNode)
+		}
+		episodeNode.Paths = appendPath(episodeNode.Paths, path)
+		addOccurrence(path, episode.JSONForClient)
+	}
+
+	var report IntegrityReport
+	sort.Ints(objectIDs)
+	for _, objectID := range objectIDs {
+		report.checkObject(objectID, occurrences[objectID])
+	}
+	var sharedSeasonIDs []int
+	for seasonID, seriesIDs := range seasonSeries {
+		if len(seriesIDs) > 1 {
+			sharedSeasonIDs = append(sharedSeasonIDs, seasonID)
+		}
+	}
+	sort.Ints(sharedSeasonIDs)
+	for _, seasonID := range sharedSeasonIDs {
+		var seriesIDs []int
+		for seriesID := range seasonSeries[seasonID] {
+			seriesIDs = append(seriesIDs, seriesID)
+		}
+		sort.Ints(seriesIDs)
+		report.add(ConflictingObject, seasonID, seasonPaths[seasonID], "recSeason is linked by episodes of %d series: %v", len(seriesIDs), seriesIDs)
+	}
+	index.Conflicts = report.Issues
+	index.sort()
+	return index
+}
+
+// season finds or creates the season node of episode. The season number comes
+// from the episode, or from the bundled recSeason when the episode has none.
+func (si *SeriesIndex) season(series *SeriesNode, episode ClientJSON, recordedSeason RecSeason) *SeasonNode {
+	seasonID := episode.Relationships.RecSeason
+	seasonNumber := episode.SeasonNumber
+	if seasonNumber == 0 && seasonID != 0 && recordedSeason.JSONForClient.ObjectID == seasonID {
+		seasonNumber = recordedSeason.JSONForClient.SeasonNumber
+	}
+	key := seasonKey{series.SeriesID, seasonID}
+	if season, wasFound := si.seasonBy[key]; wasFound && seasonID != 0 {
+		return season
+	}
+	for _, season := range series.Seasons {
+		if season.SeasonNumber == seasonNumber && (season.SeasonID == seasonID || season.SeasonID == 0 || seasonID == 0) {
+			if season.SeasonID == 0 && seasonID != 0 {
+				season.SeasonID = seasonID
+				si.seasonBy[key] = season
+			}
+			return season
+		}
+	}
+	season := &SeasonNode{SeasonID: seasonID, SeasonNumber: seasonNumber}
+	if seasonID != 0 {
+		si.seasonBy[key] = season
+	}
+	series.Seasons = append(series.Seasons, season)
+	return season
+}
+
+func (si *SeriesIndex) sort() {
+	sort.SliceStable(si.series, func(i, j int) bool {
+		if si.series[i].Title() != si.series[j].Title() {
+			return si.series[i].Title() < si.series[j].Title()
+		}
+		return si.series[i].SeriesID < si.series[j].SeriesID
+	})
+	for _, series := range si.series {
+		seasons := series.Seasons
+		sort.SliceStable(seasons, func(i, j int) bool {
+			return broadcastNumberLess(seasons[i].SeasonNumber, seasons[j].SeasonNumber)
+		})
+		for _, season := range seasons {
+			episodes := season.Episodes
+			sort.SliceStable(episodes, func(i, j int) bool {
+				a, b := episodes[i].Episode.JSONForClient, episodes[j].Episode.JSONForClient
+				if a.EpisodeNumber != b.EpisodeNumber {
+					return broadcastNumberLess(a.EpisodeNumber, b.EpisodeNumber)
+				}
+				if !a.AirDate.StoredTime.Equal(b.AirDate.StoredTime) {
+					return a.AirDate.StoredTime.Before(b.AirDate.StoredTime)
+				}
+				return a.ObjectID < b.ObjectID
+			})
+		}
+	}
+}
+
+// broadcastNumberLess orders season or episode numbers ascending with 0, the
+// specials or unnumbered entries, last.
+func broadcastNumberLess(a int, b int) bool {
+	if (a == 0) != (b == 0) {
+		return b == 0
+	}
+	return a < b
+}
+
+// Series returns every series ordered by title and then series ID.
+func (si *SeriesIndex) Series() []*SeriesNode {
+	return append([]*SeriesNode(nil), si.series...)
+}
+
+// SeriesByID returns the series with the recSeries object ID seriesID.
+func (si *SeriesIndex) SeriesByID(seriesID int) (*SeriesNode, bool) {
+	series, wasFound := si.seriesBy[seriesID]
+	return series, wasFound
+}
+
+// SeriesByTitle returns the series whose title matches title, ignoring case
+// and punctuation. Different series can share a title. A title without any
+// words matches nothing, not the series that were never bundled.
+func (si *SeriesIndex) SeriesByTitle(title string) []*SeriesNode {
+	normalizedTitle := strings.Join(normalizeWords(title), " ")
+	if len(normalizedTitle) < 1 {
+		return nil
+	}
+	var matches []*SeriesNode
+	for _, series := range si.series {
+		if strings.Join(normalizeWords(series.Title()), " ") == normalizedTitle {
+			matches = append(matches, series)
+		}
+	}
+	return matches
+}
+
+// SeasonByID returns the season with the recSeason object ID seasonID. When
+// episodes of several series link it, which Conflicts reports, the season of
+// the first series in Series order is returned.
+func (si *SeriesIndex) SeasonByID(seasonID int) (*SeasonNode, bool) {
+	if seasonID == 0 {
+		return nil, false
+	}
+	for _, series := range si.series {
+		if season, wasFound := si.seasonBy[seasonKey{series.SeriesID, seasonID}]; wasFound {
+			return season, true
+		}
+	}
+	return nil, false
+}
+
+// EpisodeByID returns the episode with the recEpisode object ID episodeID.
+func (si *SeriesIndex) EpisodeByID(episodeID int) (*EpisodeNode, bool) {
+	episode, wasFound := si.episodes[episodeID]
+	return episode, wasFound
+}
+
+func appendPath(paths []string, path string) []string {
+	if containsString(paths, path) {
+		return paths
+	}
+	return append(paths, path)
+}
@@ -0,0 +1,107 @@
+package tablometadata_test
+
+import (
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestSeriesIndexTree(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 3, "2017-09-19T05:00Z", numbered(1, 5)),
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z", numbered(1, 2)),
+		episodeAiredOn(t, 6, "2017-09-19T05:00Z", numbered(0, 1)),
+		episodeAiredOn(t, 5, "2017-09-19T05:00Z", numbered(1, 0)),
+		episodeAiredOn(t, 2, "2017-09-19T05:00Z", numbered(2, 1)),
+		episodeAiredOn(t, 4, "2017-09-19T05:00Z", numbered(1, 1)),
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+	}
+	index := tablometadata.NewSeriesIndex(recordings)
+
+	if len(index.Series()) != 1 || len(index.Conflicts) != 0 {
+		t.Fatalf("expected one clean series, got %v and %v", index.Series(), index.Conflicts)
+	}
+	series, wasFound := index.SeriesByID(301534)
+	if !wasFound || series.Title() != "Midnight, Texas" || len(series.Paths) != 6 {
+		t.Fatalf("unexpected series %+v", series)
+	}
+	if len(series.Seasons) != 3 || series.Seasons[0].SeasonNumber != 1 || series.Seasons[1].SeasonNumber != 2 || series.Seasons[2].SeasonNumber != 0 {
+		t.Errorf("expected seasons in broadcast order with specials last, got %+v", series.Seasons)
+	}
+
+	var order []int
+	for _, episode := range series.Episodes() {
+		order = append(order, episode.Episode.JSONForClient.ObjectID)
+	}
+	if len(order) != 6 || order[0] != 4 || order[1] != 1 || order[2] != 3 || order[3] != 5 || order[4] != 2 || order[5] != 6 {
+		t.Errorf("unexpected episode order %v", order)
+	}
+
+	seasonOne, _ := series.Season(1)
+	if season, wasFound := index.SeasonByID(400001); !wasFound || season != seasonOne || season.Season.JSONForClient.SeasonNumber != 1 || len(season.Paths) != 4 {
+		t.Errorf("unexpected season lookup %+v", season)
+	}
+	if episode, wasFound := index.EpisodeByID(3); !wasFound || episode.Episode.JSONForClient.EpisodeNumber != 5 || episode.Paths[0] != "rec/3/meta.txt" {
+		t.Errorf("unexpected episode lookup %+v", episode)
+	}
+	if matches := index.SeriesByTitle("midnight texas"); len(matches) != 1 || matches[0] != series {
+		t.Errorf("unexpected title lookup %v", matches)
+	}
+	if _, wasFound := index.SeriesByID(117666); wasFound {
+		t.Error("expected movies to be left out")
+	}
+}
+
+func TestSeriesIndexFlagsConflictingCopies(t *testing.T) {
+	recordings := []tablometadata.LibraryRecording{
+		episodeAiredOn(t, 1, "2017-09-19T05:00Z", numbered(1, 1)),
+		episodeAiredOn(t, 2, "2017-09-26T05:00Z", func(recording *tablometadata.Recording) {
+			recording.RecordedSeries.JSONForClient.Title = "Midnight Texas"
+		}),
+	}
+	index := tablometadata.NewSeriesIndex(recordings)
+
+	if len(index.Series()) != 1 {
+		t.Fatalf("expected the copies to be merged, got %v", index.Series())
+	}
+	if len(index.Conflicts) != 1 || index.Conflicts[0].Kind != tablometadata.ConflictingObject || index.Conflicts[0].ObjectID != 301534 {
+		t.Fatalf("expected a series title conflict, got %v", index.Conflicts)
+	}
+	if paths := index.Conflicts[0].Paths; len(paths) != 2 {
+		t.Errorf("expected both meta files, got %v", paths)
+	}
+	if series, _ := index.SeriesByID(301534); series.Title() != "Midnight, Texas" {
+		t.Errorf("expected the first copy to be kept, got %q", series.Title())
+	}
+}
+
+func TestSeriesIndexKeepsSeasonsPerSeries(t *testing.T) {
+	other := episodeAiredOn(t, 2, "2017-09-19T05:00Z", numbered(1, 2))
+	other.Recording.RecordedEpisode.JSONForClient.Relationships.RecSeries = 501534
+	other.Recording.RecordedSeries.JSONForClient.ObjectID = 501534
+	other.Recording.RecordedSeries.JSONForClient.Title = "Nova"
+	index := tablometadata.NewSeriesIndex([]tablometadata.LibraryRecording{episodeAiredOn(t, 1, "2017-09-19T05:00Z", numbered(1, 1)), other})
+
+	series := index.Series()
+	if len(series) != 2 {
+		t.Fatalf("expected two series, got %v", series)
+	}
+	for _, node := range series {
+		if season, wasFound := node.Season(1); !wasFound || len(season.Episodes) != 1 {
+			t.Errorf("%s: expected a season 1 with its own episode, got %+v", node.Title(), season)
+		}
+	}
+	if len(index.Conflicts) != 1 || index.Conflicts[0].ObjectID != 400001 || len(index.Conflicts[0].Paths) != 2 {
+		t.Errorf("expected the shared season ID to be reported, got %v", index.Conflicts)
+	}
+	if season, _ := index.SeasonByID(400001); season == nil || season.Episodes[0].Episode.JSONForClient.ObjectID != 1 {
+		t.Errorf("expected the season of the first series, got %+v", season)
+	}
+
+	if matches := index.SeriesByTitle(""); matches != nil {
+		t.Errorf("expected an empty title to match nothing, got %v", matches)
+	}
+	if matches := index.SeriesByTitle("nova"); len(matches) != 1 || matches[0].SeriesID != 501534 {
+		t.Errorf("unexpected matches %v", matches)
+	}
+}
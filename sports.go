@@ -0,0 +1,184 @@
+package tablometadata
+
+// SportEventClient is the jsonForClient of a recSportEvent. Unlike the typed
+// clients in clients.go it is the storage type of its wrapper, so the fields
+// only sport events have stay out of ClientJSON. ClientJSON returns the fields
+// the two share.
+type SportEventClient struct {
+	Type             string        `json:"type"`
+	Title            string        `json:"title"`
+	EventTitle       string        `json:"eventTitle"`
+	Description      string        `json:"description"`
+	Season           string        `json:"season"`
+	HomeTeamID       int           `json:"homeTeamID"`
+	AwayTeamID       int           `json:"awayTeamID"`
+	AirDate          TabloDate     `json:"airDate"`
+	ScheduleDuration float32       `json:"scheduleDuration"`
+	Qualifiers       []string      `json:"qualifiers"`
+	Relationships    Relationships `json:"relationships"`
+	Video            VideoInfo     `json:"video"`
+	User             UserInfo      `json:"user"`
+	ObjectID         int           `json:"objectID"`
+
+	layout *objectLayout
+}
+
+func (sec *SportEventClient) setLayout(layout *objectLayout) {
+	sec.layout = layout
+}
+
+func (sec *SportEventClient) clientType() string {
+	return "recSportEvent"
+}
+
+func (sec *SportEventClient) UnmarshalJSON(data []byte) error {
+	type plain SportEventClient
+	return decodeClient(data, sec, (*plain)(sec), sec.layout)
+}
+
+func (sec SportEventClient) MarshalJSON() ([]byte, error) {
+	if sec.layout != nil {
+		return encodeWithLayout(sec.layout, sec)
+	}
+	encoder := newObjectEncoder(sec)
+	encoder.String("Type", sec.Type)
+	encoder.String("Title", sec.Title)
+	encoder.String("EventTitle", sec.EventTitle)
+	encoder.String("Description", sec.Description)
+	encoder.String("Season", sec.Season)
+	encoder.Int("HomeTeamID", sec.HomeTeamID)
+	encoder.Int("AwayTeamID", sec.AwayTeamID)
+	encoder.Value("AirDate", sec.AirDate)
+	encoder.Float("ScheduleDuration", sec.ScheduleDuration, 0)
+	encoder.Value("Qualifiers", sec.Qualifiers)
+	encoder.Value("Relationships", sec.Relationships)
+	encoder.Value("Video", sec.Video)
+	encoder.Value("User", sec.User)
+	encoder.Int("ObjectID", sec.ObjectID)
+	return encoder.Bytes()
+}
+
+func (sec SportEventClient) Validate() error {
+	vd := &validator{}
+	vd.check(sec.ObjectID > 0, "objectID", "must be positive, got %d", sec.ObjectID)
+	vd.check(!sec.AirDate.StoredTime.IsZero(), "airDate", "must be set")
+	vd.check(sec.ScheduleDuration >= 0, "scheduleDuration", "must not be negative, got %.0f", sec.ScheduleDuration)
+	vd.nested("video.", sec.Video.validate)
+	vd.nested("user.", sec.User.validate)
+	return vd.err()
+}
+
+func (sec SportEventClient) ClientJSON() ClientJSON {
+	return ClientJSON{Type: "recSportEvent", Title: sec.Title, Description: sec.Description, AirDate: sec.AirDate,
+		ScheduleDuration: sec.ScheduleDuration, Qualifiers: sec.Qualifiers, Relationships: sec.Relationships,
+		Video: sec.Video, User: sec.User, ObjectID: sec.ObjectID, layout: sec.layout}
+}
+
+func (sec SportEventClient) displayTitle() string {
+	if len(sec.EventTitle) > 0 {
+		return sec.EventTitle
+	}
+	return sec.Title
+}
+
+type RecSportEvent struct {
+	JSONForClient SportEventClient `json:"jsonForClient"`
+	ImageJSON     ImageJSONData    `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (se *RecSportEvent) setLayout(layout *objectLayout) {
+	se.layout = layout
+}
+
+func (se RecSportEvent) MarshalJSON() ([]byte, error) {
+	if se.layout != nil {
+		return encodeWithLayout(se.layout, se)
+	}
+	encoder := newObjectEncoder(se)
+	encoder.Value("JSONForClient", se.JSONForClient)
+	encoder.Value("ImageJSON", se.ImageJSON)
+	return encoder.Bytes()
+}
+
+func (se *RecSportEvent) GetTabloType() string {
+	return se.JSONForClient.Type
+}
+
+type RecSportOrganization struct {
+	JSONForClient ClientJSON    `json:"jsonForClient"`
+	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (so *RecSportOrganization) setLayout(layout *objectLayout) {
+	so.layout = layout
+}
+
+func (so RecSportOrganization) MarshalJSON() ([]byte, error) {
+	if so.layout != nil {
+		return encodeWithLayout(so.layout, so)
+	}
+	encoder := newObjectEncoder(so)
+	encoder.Value("JSONForClient", so.JSONForClient)
+	encoder.Value("ImageJSON", so.ImageJSON)
+	return encoder.Bytes()
+}
+
+func (so *RecSportOrganization) GetTabloType() string {
+	return so.JSONForClient.Type
+}
+
+type RecSportTeam struct {
+	JSONForClient ClientJSON    `json:"jsonForClient"`
+	ImageJSON     ImageJSONData `json:"imageJson"`
+
+	layout *objectLayout
+}
+
+func (st *RecSportTeam) setLayout(layout *objectLayout) {
+	st.layout = layout
+}
+
+func (st RecSportTeam) MarshalJSON() ([]byte, error) {
+	if st.layout != nil {
+		return encodeWithLayout(st.layout, st)
+	}
+	encoder := newObjectEncoder(st)
+	encoder.Value("JSONForClient", st.JSONForClient)
+	encoder.Value("ImageJSON", st.ImageJSON)
+	return encoder.Bytes()
+}
+
+func (st *RecSportTeam) GetTabloType() string {
+	return st.JSONForClient.Type
+}
+
+// HomeTeam returns the bundled team the sport event lists as its home team.
+func (tr Recording) HomeTeam() (RecSportTeam, bool) {
+	return tr.sportTeam(tr.RecordedSportEvent.JSONForClient.HomeTeamID)
+}
+
+// AwayTeam returns the bundled team the sport event lists as its away team.
+func (tr Recording) AwayTeam() (RecSportTeam, bool) {
+	return tr.sportTeam(tr.RecordedSportEvent.JSONForClient.AwayTeamID)
+}
+
+// League is the title of the organization the sport event belongs to.
+func (tr Recording) League() string {
+	return tr.RecordedSportOrganization.JSONForClient.Title
+}
+
+func (tr Recording) sportTeam(objectID int) (RecSportTeam, bool) {
+	if objectID == 0 {
+		return RecSportTeam{}, false
+	}
+	for _, team := range tr.RecordedSportTeams {
+		if team.JSONForClient.ObjectID == objectID {
+			return team, true
+		}
+	}
+	return RecSportTeam{}, false
+}
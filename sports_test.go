@@ -0,0 +1,75 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+// sampleSportEventJSON lists its members in a different order from the
+// canonical encoding, so a decode that only works for the encoder's own output
+// is caught.
+const sampleSportEventJSON = `{"recSportTeams":[{"imageJson":{"images":[]},"jsonForClient":{"type":"recSportTeam","objectID":9001,"title":"New York Giants","relationships":{"recSportOrganization":8001},"description":""}},{"imageJson":{"images":[]},"jsonForClient":{"type":"recSportTeam","objectID":9002,"title":"Dallas Cowboys","relationships":{"recSportOrganization":8001},"description":""}}],"recSportOrganization":{"imageJson":{"images":[]},"jsonForClient":{"type":"recSportOrganization","objectID":8001,"title":"NFL","description":"National Football League","relationships":{}}},"recSportEvent":{"imageJson":{"images":[]},"jsonForClient":{"objectID":400100,"type":"recSportEvent","airDate":"2017-09-11T00:30Z","scheduleDuration":12600,"title":"NFL Football","season":"2017","eventTitle":"Dallas Cowboys at New York Giants","awayTeamID":9002,"homeTeamID":9001,"description":"From MetLife Stadium.","relationships":{"recChannel":185238,"recSportOrganization":8001,"recSportTeams":[9001,9002]},"qualifiers":["cc","live"],"user":{"position":0.0,"protected":true,"type":"recordingUserInfo","watched":false},"video":{"duration":14400.0,"height":1080,"scheduleOffsetEnd":1800.0,"scheduleOffsetStart":-15.0,"size":9302616064,"state":"finished","width":1920}}},"recChannel":{"jsonForClient":{"objectID":185238,"type":"recChannel","channel":{"major":5,"minor":1,"callSign":"KXAS-HD"}}}}`
+
+func TestParseSportEvent(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleSportEventJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+
+	event := recording.RecordedSportEvent.JSONForClient
+	if recording.RecordedSportEvent.GetTabloType() != "recSportEvent" || event.EventTitle != "Dallas Cowboys at New York Giants" || event.Season != "2017" {
+		t.Errorf("unexpected sport event %+v", event)
+	}
+	if event.ObjectID != 400100 || event.ScheduleDuration != 12600 || event.Video.Duration != 14400 || !event.User.Protected {
+		t.Errorf("unexpected sport event %+v", event)
+	}
+	if recording.League() != "NFL" {
+		t.Errorf("expected league NFL, got %q", recording.League())
+	}
+	home, found := recording.HomeTeam()
+	if !found || home.JSONForClient.Title != "New York Giants" {
+		t.Errorf("unexpected home team %+v", home.JSONForClient)
+	}
+	away, found := recording.AwayTeam()
+	if !found || away.JSONForClient.Title != "Dallas Cowboys" {
+		t.Errorf("unexpected away team %+v", away.JSONForClient)
+	}
+
+	jsonData, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(jsonData), `{"recSportEvent":{"jsonForClient":{"type":"recSportEvent","title":"NFL Football","eventTitle":"Dallas Cowboys at New York Giants"`) {
+		t.Errorf("expected the canonical layout, got %s", jsonData)
+	}
+	var decoded tablometadata.Recording
+	if err := json.Unmarshal(jsonData, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, recording) {
+		t.Errorf("canonical encoding did not decode to the same recording:\n got %+v\nwant %+v", decoded, recording)
+	}
+}
+
+func TestSportEventLosslessRoundTrip(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := tablometadata.UnmarshalLossless([]byte(sampleSportEventJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := recording.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(jsonData) != sampleSportEventJSON {
+		t.Errorf("round trip changed the document:\n got %s\nwant %s", jsonData, sampleSportEventJSON)
+	}
+
+	var client tablometadata.SportEventClient
+	if err := json.Unmarshal([]byte(`{"type":"recEpisode","objectID":1}`), &client); err == nil {
+		t.Error("expected decoding an episode into a SportEventClient to fail")
+	}
+}
@@ -0,0 +1,163 @@
+package tablometadata
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RecordingState is the video state Tablo writes in VideoInfo.State. States
+// this package does not know are kept as they were read; see Known.
+type RecordingState string
+
+const (
+	StateRecording RecordingState = "recording"
+	StateFinished  RecordingState = "finished"
+	StateFailed    RecordingState = "failed"
+)
+
+// Known reports whether rs is one of the states this package defines. An
+// unknown state still holds the raw value from the meta file.
+func (rs RecordingState) Known() bool {
+	switch rs {
+	case StateRecording, StateFinished, StateFailed:
+		return true
+	}
+	return false
+}
+
+// Terminal reports whether the recording can no longer change state.
+func (rs RecordingState) Terminal() bool {
+	return rs == StateFinished || rs == StateFailed
+}
+
+// StateTransition is a change of a recording's state between two
+// observations. From is empty for the first observation of a recording.
+type StateTransition struct {
+	ObjectID int
+	From     RecordingState
+	To       RecordingState
+	At       time.Time
+}
+
+type trackedRecording struct {
+	state       RecordingState
+	lastSeen    time.Time
+	transitions []StateTransition
+}
+
+// StateTracker follows the state of recordings whose meta files are observed
+// repeatedly, for example by rescanning a library, and records every change
+// with the time it was first seen. Observations of a recording must be made in
+// time order; one older than the latest is ignored. It is safe for concurrent
+// use.
+type StateTracker struct {
+	mutex      sync.Mutex
+	recordings map[int]*trackedRecording
+}
+
+func NewStateTracker() *StateTracker {
+	return &StateTracker{recordings: make(map[int]*trackedRecording)}
+}
+
+// Observe records that the recording objectID was in state at the given time.
+// It returns the transition when the state differs from the previous
+// observation.
+func (st *StateTracker) Observe(objectID int, state RecordingState, at time.Time) (StateTransition, bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	tracked, wasFound := st.recordings[objectID]
+	if !wasFound {
+		tracked = &trackedRecording{}
+		st.recordings[objectID] = tracked
+	} else if at.Before(tracked.lastSeen) {
+		return StateTransition{}, false
+	}
+	tracked.lastSeen = at
+	if wasFound && tracked.state == state {
+		return StateTransition{}, false
+	}
+
+	transition := StateTransition{ObjectID: objectID, From: tracked.state, To: state, At: at}
+	tracked.state = state
+	tracked.transitions = append(tracked.transitions, transition)
+	return transition, true
+}
+
+// ObserveLibrary observes the state of every recording in the library at the
+// given time and returns the transitions it caused.
+func (st *StateTracker) ObserveLibrary(library *Library, at time.Time) []StateTransition {
+	var transitions []StateTransition
+	for i := range library.Recordings {
+		primary := library.Recordings[i].Recording.Primary()
+		if primary == nil {
+			continue
+		}
+		if transition, changed := st.Observe(library.Recordings[i].ObjectID, clientOf(primary).Video.State, at); changed {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions
+}
+
+// State returns the last observed state of objectID.
+func (st *StateTracker) State(objectID int) (RecordingState, bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	tracked, wasFound := st.recordings[objectID]
+	if !wasFound {
+		return "", false
+	}
+	return tracked.state, true
+}
+
+// Transitions returns every transition of objectID, oldest first.
+func (st *StateTracker) Transitions(objectID int) []StateTransition {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	tracked, wasFound := st.recordings[objectID]
+	if !wasFound {
+		return nil
+	}
+	return append([]StateTransition(nil), tracked.transitions...)
+}
+
+// TimeIn returns how long objectID was observed in state. Time in the current
+// state is counted up to the latest observation.
+func (st *StateTracker) TimeIn(objectID int, state RecordingState) time.Duration {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	tracked, wasFound := st.recordings[objectID]
+	if !wasFound {
+		return 0
+	}
+
+	var total time.Duration
+	for i, transition := range tracked.transitions {
+		if transition.To != state {
+			continue
+		}
+		end := tracked.lastSeen
+		if i+1 < len(tracked.transitions) {
+			end = tracked.transitions[i+1].At
+		}
+		total += end.Sub(transition.At)
+	}
+	return total
+}
+
+// Failed returns the object IDs whose last observed state is StateFailed, in
+// ascending order.
+func (st *StateTracker) Failed() []int {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	var objectIDs []int
+	for objectID, tracked := range st.recordings {
+		if tracked.state == StateFailed {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	sort.Ints(objectIDs)
+	return objectIDs
+}
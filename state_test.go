@@ -0,0 +1,75 @@
+package tablometadata_test
+
+import (
+	"testing"
+	"time"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestRecordingStateKeepsUnknownValues(t *testing.T) {
+	var video tablometadata.VideoInfo
+	if err := tablometadata.UnmarshalLossless([]byte(`{"state":"conflicted","size":0}`), &video); err != nil {
+		t.Fatal(err)
+	}
+	if video.State.Known() || video.State != "conflicted" {
+		t.Errorf("expected the raw unknown state, got %q", video.State)
+	}
+	if encoded, _ := video.MarshalJSON(); string(encoded) != `{"state":"conflicted","size":0}` {
+		t.Errorf("unexpected encoding %s", encoded)
+	}
+	if !tablometadata.StateFinished.Known() || !tablometadata.StateFailed.Terminal() || tablometadata.StateRecording.Terminal() {
+		t.Error("unexpected state classification")
+	}
+}
+
+func TestStateTracker(t *testing.T) {
+	tracker := tablometadata.NewStateTracker()
+	start := time.Date(2017, 9, 19, 5, 0, 0, 0, time.UTC)
+
+	if transition, changed := tracker.Observe(1, tablometadata.StateRecording, start); !changed || transition.From != "" {
+		t.Errorf("expected the first observation to be a transition, got %+v", transition)
+	}
+	tracker.Observe(1, tablometadata.StateRecording, start.Add(30*time.Minute))
+	tracker.Observe(1, tablometadata.StateFinished, start.Add(time.Hour))
+	tracker.Observe(1, tablometadata.StateFinished, start.Add(2*time.Hour))
+	if _, changed := tracker.Observe(1, tablometadata.StateFailed, start.Add(-time.Hour)); changed {
+		t.Error("expected an out of order observation to be ignored")
+	}
+	tracker.Observe(2, tablometadata.StateRecording, start)
+	tracker.Observe(2, tablometadata.StateFailed, start.Add(10*time.Minute))
+
+	transitions := tracker.Transitions(1)
+	if len(transitions) != 2 || transitions[1].From != tablometadata.StateRecording || transitions[1].To != tablometadata.StateFinished || !transitions[1].At.Equal(start.Add(time.Hour)) {
+		t.Errorf("unexpected transitions %+v", transitions)
+	}
+	if inProgress := tracker.TimeIn(1, tablometadata.StateRecording); inProgress != time.Hour {
+		t.Errorf("expected an hour in progress, got %v", inProgress)
+	}
+	if finished := tracker.TimeIn(1, tablometadata.StateFinished); finished != time.Hour {
+		t.Errorf("expected an hour finished, got %v", finished)
+	}
+	if state, _ := tracker.State(2); state != tablometadata.StateFailed {
+		t.Errorf("unexpected state %q", state)
+	}
+	if failed := tracker.Failed(); len(failed) != 1 || failed[0] != 2 {
+		t.Errorf("unexpected failures %v", failed)
+	}
+}
+
+func TestStateTrackerObserveLibrary(t *testing.T) {
+	recording := decodeLibraryRecording(t, "episode", sampleEpisodeJSON)
+	recording.Recording.RecordedEpisode.JSONForClient.Video.State = tablometadata.StateRecording
+	library := &tablometadata.Library{Recordings: []tablometadata.LibraryRecording{recording}}
+	tracker := tablometadata.NewStateTracker()
+	start := time.Date(2017, 9, 19, 5, 0, 0, 0, time.UTC)
+
+	if transitions := tracker.ObserveLibrary(library, start); len(transitions) != 1 || transitions[0].ObjectID != 343176 {
+		t.Errorf("unexpected transitions %+v", transitions)
+	}
+	library.Recordings[0] = decodeLibraryRecording(t, "episode", sampleEpisodeJSON)
+	transitions := tracker.ObserveLibrary(library, start.Add(90*time.Minute))
+	if len(transitions) != 1 || transitions[0].To != tablometadata.StateFinished {
+		t.Errorf("unexpected transitions %+v", transitions)
+	}
+}
@@ -0,0 +1,170 @@
+package tablometadata
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StorageEntry totals the recordings of one series, season, channel, genre or
+// year. Bytes and Duration come from VideoInfo.Size and VideoInfo.Duration;
+// WatchedBytes is the part of Bytes whose recordings are marked watched.
+type StorageEntry struct {
+	ID           int
+	Label        string
+	Recordings   int
+	Bytes        uint64
+	Duration     time.Duration
+	WatchedBytes uint64
+}
+
+// Hours returns Duration in hours.
+func (se StorageEntry) Hours() float64 {
+	return se.Duration.Hours()
+}
+
+// WatchedShare returns the share of Bytes taken by watched recordings, from 0
+// to 1.
+func (se StorageEntry) WatchedShare() float64 {
+	if se.Bytes == 0 {
+		return 0
+	}
+	return float64(se.WatchedBytes) / float64(se.Bytes)
+}
+
+func (se *StorageEntry) add(client ClientJSON) {
+	se.Recordings++
+	se.Bytes += client.Video.Size
+	se.Duration += secondsDuration(client.Video.Duration)
+	if client.User.Watched {
+		se.WatchedBytes += client.Video.Size
+	}
+}
+
+// StorageOptions configures AnalyzeStorage. Location decides which year a
+// recording made around midnight on New Year's Eve falls in; nil uses UTC.
+type StorageOptions struct {
+	Location *time.Location
+}
+
+// StorageReport is the space used by a library. Every list is sorted largest
+// consumer first. Recordings that are not episodes have no series or season,
+// and a recording with several genres counts in full towards each of them, so
+// the genre totals can add up to more than Total.
+type StorageReport struct {
+	Total     StorageEntry
+	BySeries  []StorageEntry
+	BySeason  []StorageEntry
+	ByChannel []StorageEntry
+	ByGenre   []StorageEntry
+	ByYear    []StorageEntry
+}
+
+// Share returns the share of the library's bytes taken by entry, from 0 to 1.
+func (sr StorageReport) Share(entry StorageEntry) float64 {
+	if sr.Total.Bytes == 0 {
+		return 0
+	}
+	return float64(entry.Bytes) / float64(sr.Total.Bytes)
+}
+
+// AnalyzeStorage runs the package level AnalyzeStorage over every recording in
+// the library.
+func (tl *Library) AnalyzeStorage(options StorageOptions) StorageReport {
+	return AnalyzeStorage(tl.Recordings, options)
+}
+
+// AnalyzeStorage totals the bytes and hours of every recording per series,
+// season, channel, genre and recording year, using the relationships of the
+// recorded object.
+func AnalyzeStorage(recordings []LibraryRecording, options StorageOptions) StorageReport {
+	loc := locationOrUTC(options.Location)
+
+	var report StorageReport
+	series := make(storageEntries)
+	seasons := make(storageEntries)
+	channels := make(storageEntries)
+	genres := make(storageEntries)
+	years := make(storageEntries)
+	for i := range recordings {
+		recording := &recordings[i].Recording
+		primary := recording.Primary()
+		if primary == nil {
+			continue
+		}
+		client := clientOf(primary)
+		report.Total.add(client)
+
+		relationships := client.Relationships
+		if seriesID := relationships.RecSeries; seriesID != 0 {
+			seriesTitle := fmt.Sprintf("series %d", seriesID)
+			if recordedSeries := recording.RecordedSeries.JSONForClient; recordedSeries.ObjectID == seriesID && len(recordedSeries.Title) > 0 {
+				seriesTitle = recordedSeries.Title
+			}
+			series.add(seriesID, seriesTitle, client)
+			if seasonID := relationships.RecSeason; seasonID != 0 {
+				seasons.add(seasonID, fmt.Sprintf("%s season %d", seriesTitle, client.SeasonNumber), client)
+			}
+		}
+		if channelID := relationships.RecChannel; channelID != 0 {
+			channels.add(channelID, fmt.Sprintf("channel %d", channelID), client)
+		}
+		for _, genreID := range recordingGenres(recording, client) {
+			genres.add(genreID, fmt.Sprintf("genre %d", genreID), client)
+		}
+		if airTime, wasFound := primary.AirTime(); wasFound {
+			year := airTime.In(loc).Year()
+			years.add(year, fmt.Sprint(year), client)
+		}
+	}
+
+	report.Total.Label = "total"
+	report.BySeries = series.sorted()
+	report.BySeason = seasons.sorted()
+	report.ByChannel = channels.sorted()
+	report.ByGenre = genres.sorted()
+	report.ByYear = years.sorted()
+	return report
+}
+
+// recordingGenres returns the genres of the recorded object, which Tablo links
+// from the series or movie rather than from the airing itself.
+func recordingGenres(recording *Recording, client ClientJSON) []int {
+	seen := make(map[int]bool)
+	var genres []int
+	for _, genreLists := range [][]int{client.Relationships.Genres, recording.RecordedSeries.JSONForClient.Relationships.Genres,
+		recording.RecordedMovie.JSONForClient.Relationships.Genres} {
+		for _, genreID := range genreLists {
+			if !seen[genreID] {
+				seen[genreID] = true
+				genres = append(genres, genreID)
+			}
+		}
+	}
+	return genres
+}
+
+type storageEntries map[int]*StorageEntry
+
+func (se storageEntries) add(id int, label string, client ClientJSON) {
+	entry, wasFound := se[id]
+	if !wasFound {
+		entry = &StorageEntry{ID: id, Label: label}
+		se[id] = entry
+	}
+	entry.add(client)
+}
+
+func (se storageEntries) sorted() []StorageEntry {
+	entries := make([]StorageEntry, 0, len(se))
+	for _, entry := range se {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Bytes != entries[j].Bytes {
+			return entries[i].Bytes > entries[j].Bytes
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
@@ -0,0 +1,62 @@
+package tablometadata_test
+
+import (
+	"testing"
+	"time"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestAnalyzeStorage(t *testing.T) {
+	watched := decodeLibraryRecording(t, "watched", sampleEpisodeJSON)
+	watched.Recording.RecordedEpisode.JSONForClient.User.Watched = true
+	watched.Recording.RecordedEpisode.JSONForClient.ObjectID = 343177
+	recordings := []tablometadata.LibraryRecording{
+		decodeLibraryRecording(t, "episode", sampleEpisodeJSON),
+		watched,
+		decodeLibraryRecording(t, "movie", sampleMovieJSON),
+	}
+	report := tablometadata.AnalyzeStorage(recordings, tablometadata.StorageOptions{})
+
+	const episodeSize, movieSize = 5302616064, 3415293952
+	if report.Total.Recordings != 3 || report.Total.Bytes != 2*episodeSize+movieSize || report.Total.Duration != (2*5417+7520)*time.Second {
+		t.Errorf("unexpected total %+v", report.Total)
+	}
+	if share := report.Total.WatchedShare(); share < 0.3 || share > 0.4 {
+		t.Errorf("unexpected watched share %f", share)
+	}
+
+	if len(report.BySeries) != 1 || report.BySeries[0].Label != "Midnight, Texas" || report.BySeries[0].Bytes != 2*episodeSize ||
+		report.BySeries[0].WatchedShare() != 0.5 {
+		t.Errorf("unexpected series %+v", report.BySeries)
+	}
+	if len(report.BySeason) != 1 || report.BySeason[0].ID != 301535 || report.BySeason[0].Label != "Midnight, Texas season 1" {
+		t.Errorf("unexpected seasons %+v", report.BySeason)
+	}
+	if len(report.ByChannel) != 2 || report.ByChannel[0].ID != 185238 || report.ByChannel[1].ID != 5465 {
+		t.Errorf("expected channels largest first, got %+v", report.ByChannel)
+	}
+	if len(report.ByGenre) != 2 || report.ByGenre[0].ID != 108 || report.ByGenre[1].ID != 1063 || report.ByGenre[1].Recordings != 1 {
+		t.Errorf("unexpected genres %+v", report.ByGenre)
+	}
+	if len(report.ByYear) != 2 || report.ByYear[0].Label != "2017" || report.ByYear[1].Label != "2016" {
+		t.Errorf("unexpected years %+v", report.ByYear)
+	}
+	if share := report.Share(report.BySeries[0]); share < 0.7 || share > 0.8 {
+		t.Errorf("unexpected series share %f", share)
+	}
+}
+
+func TestAnalyzeStorageYearInLocation(t *testing.T) {
+	newYear := decodeLibraryRecording(t, "movie", sampleMovieJSON)
+	newYear.Recording.Airing.JSONForClient.AirDate.StoredTime = time.Date(2017, 1, 1, 3, 0, 0, 0, time.UTC)
+	recordings := []tablometadata.LibraryRecording{newYear}
+
+	if report := tablometadata.AnalyzeStorage(recordings, tablometadata.StorageOptions{}); report.ByYear[0].ID != 2017 {
+		t.Errorf("expected 2017 in UTC, got %+v", report.ByYear)
+	}
+	options := tablometadata.StorageOptions{Location: loadLocation(t, "America/Chicago")}
+	if report := tablometadata.AnalyzeStorage(recordings, options); report.ByYear[0].ID != 2016 {
+		t.Errorf("expected 2016 in Chicago, got %+v", report.ByYear)
+	}
+}
@@ -0,0 +1,146 @@
+package tablometadata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is a single structural problem, located by the JSON path of
+// the offending field.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (ve ValidationError) Error() string {
+	if len(ve.Field) < 1 {
+		return ve.Message
+	}
+	return fmt.Sprintf("%s: %s", ve.Field, ve.Message)
+}
+
+// ValidationErrors lists every problem found in one pass. Validate methods
+// return it as their error, or nil when nothing is wrong.
+type ValidationErrors []ValidationError
+
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, len(ve))
+	for i, validationError := range ve {
+		messages[i] = validationError.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+type validator struct {
+	prefix   string
+	problems ValidationErrors
+}
+
+func (vd *validator) check(ok bool, field string, format string, args ...interface{}) {
+	if !ok {
+		vd.problems = append(vd.problems, ValidationError{Field: vd.prefix + field, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// nested validates with field paths prefixed by prefix, e.g. "video.".
+func (vd *validator) nested(prefix string, validate func(nested *validator)) {
+	nested := &validator{prefix: vd.prefix + prefix}
+	validate(nested)
+	vd.problems = append(vd.problems, nested.problems...)
+}
+
+func (vd *validator) err() error {
+	if len(vd.problems) == 0 {
+		return nil
+	}
+	return vd.problems
+}
+
+func (vr VideoInfo) validate(vd *validator) {
+	vd.check(vr.Width >= 0, "width", "must not be negative, got %d", vr.Width)
+	vd.check(vr.Height >= 0, "height", "must not be negative, got %d", vr.Height)
+	vd.check(vr.Duration >= 0, "duration", "must not be negative, got %.1f", vr.Duration)
+}
+
+func (ur UserInfo) validate(vd *validator) {
+	vd.check(ur.Position >= 0, "position", "must not be negative, got %.1f", ur.Position)
+}
+
+// Validate reports every structural problem in the recording at once: invalid
+// objects, relationships that do not point at the bundled objects, and movie
+// and episode halves populated together. It returns ValidationErrors or nil.
+func (tr Recording) Validate() error {
+	vd := &validator{}
+	episode := tr.RecordedEpisode.JSONForClient
+	series := tr.RecordedSeries.JSONForClient
+	season := tr.RecordedSeason.JSONForClient
+	airing := tr.Airing.JSONForClient
+	movie := tr.RecordedMovie.JSONForClient
+	event := tr.RecordedSportEvent.JSONForClient
+
+	hasEpisode := len(episode.Type) > 0 || len(series.Type) > 0 || len(season.Type) > 0
+	hasMovie := len(airing.Type) > 0 || len(movie.Type) > 0
+	vd.check(!(hasEpisode && hasMovie), "", "both the movie (recMovieAiring/recMovie) and episode (recEpisode/recSeries/recSeason) halves are populated")
+	vd.check(tr.Primary() != nil, "", "no recorded object (episode, movie airing, sport event or program) is present")
+
+	validateClient := func(memberName string, expectedType string, clientType string, validate func() error) {
+		if len(clientType) < 1 {
+			return
+		}
+		prefix := memberName + ".jsonForClient."
+		vd.check(clientType == expectedType, prefix+"type", "expected %q, got %q", expectedType, clientType)
+		if err := validate(); err != nil {
+			for _, validationError := range err.(ValidationErrors) {
+				vd.check(false, prefix+validationError.Field, "%s", validationError.Message)
+			}
+		}
+	}
+	validateClient("recEpisode", "recEpisode", episode.Type, episode.Validate)
+	validateClient("recSeries", "recSeries", series.Type, series.Validate)
+	validateClient("recSeason", "recSeason", season.Type, season.Validate)
+	validateClient("recMovieAiring", "recMovieAiring", airing.Type, airing.Validate)
+	validateClient("recMovie", "recMovie", movie.Type, movie.Validate)
+	validateClient("recSportEvent", "recSportEvent", event.Type, event.Validate)
+	validateClient("recSportOrganization", "recSportOrganization", tr.RecordedSportOrganization.JSONForClient.Type, tr.RecordedSportOrganization.JSONForClient.Validate)
+	for i, team := range tr.RecordedSportTeams {
+		validateClient(fmt.Sprintf("recSportTeams[%d]", i), "recSportTeam", team.JSONForClient.Type, team.JSONForClient.Validate)
+	}
+	validateClient("recManualProgram", "recManualProgram", tr.RecordedManualProgram.JSONForClient.Type, tr.RecordedManualProgram.JSONForClient.Validate)
+	validateClient("recProgram", "recProgram", tr.RecordedProgram.JSONForClient.Type, tr.RecordedProgram.JSONForClient.Validate)
+	validateClient("recChannel", "recChannel", tr.RecordedChannel.JSONForClient.Type, tr.RecordedChannel.JSONForClient.Validate)
+
+	if len(episode.Type) > 0 {
+		if len(season.Type) > 0 {
+			vd.check(episode.Relationships.RecSeason == season.ObjectID, "recEpisode.jsonForClient.relationships.recSeason",
+				"points at season %d but the bundled recSeason is %d", episode.Relationships.RecSeason, season.ObjectID)
+			vd.check(episode.SeasonNumber == season.SeasonNumber, "recEpisode.jsonForClient.seasonNumber",
+				"is %d but the bundled recSeason is season %d", episode.SeasonNumber, season.SeasonNumber)
+		}
+		if len(series.Type) > 0 {
+			vd.check(episode.Relationships.RecSeries == series.ObjectID, "recEpisode.jsonForClient.relationships.recSeries",
+				"points at series %d but the bundled recSeries is %d", episode.Relationships.RecSeries, series.ObjectID)
+		}
+	}
+	if len(season.Type) > 0 && len(series.Type) > 0 {
+		vd.check(season.Relationships.RecSeries == series.ObjectID, "recSeason.jsonForClient.relationships.recSeries",
+			"points at series %d but the bundled recSeries is %d", season.Relationships.RecSeries, series.ObjectID)
+	}
+	if len(airing.Type) > 0 && len(movie.Type) > 0 {
+		vd.check(airing.Relationships.RecMovie == movie.ObjectID, "recMovieAiring.jsonForClient.relationships.recMovie",
+			"points at movie %d but the bundled recMovie is %d", airing.Relationships.RecMovie, movie.ObjectID)
+	}
+
+	if len(event.Type) > 0 {
+		if organization := tr.RecordedSportOrganization.JSONForClient; len(organization.Type) > 0 {
+			vd.check(event.Relationships.RecSportOrganization == organization.ObjectID, "recSportEvent.jsonForClient.relationships.recSportOrganization",
+				"points at organization %d but the bundled recSportOrganization is %d", event.Relationships.RecSportOrganization, organization.ObjectID)
+		}
+		if len(tr.RecordedSportTeams) > 0 {
+			_, hasHome := tr.HomeTeam()
+			_, hasAway := tr.AwayTeam()
+			vd.check(event.HomeTeamID == 0 || hasHome, "recSportEvent.jsonForClient.homeTeamID", "team %d is not in recSportTeams", event.HomeTeamID)
+			vd.check(event.AwayTeamID == 0 || hasAway, "recSportEvent.jsonForClient.awayTeamID", "team %d is not in recSportTeams", event.AwayTeamID)
+		}
+	}
+	return vd.err()
+}
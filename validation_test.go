@@ -0,0 +1,102 @@
+package tablometadata_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tablometadata "github.com/phutson/tablometa"
+)
+
+func TestValidateSamples(t *testing.T) {
+	for _, sampleJSON := range []string{sampleMovieJSON, sampleEpisodeJSON, sampleSportEventJSON, sampleManualProgramJSON, sampleProgramJSON} {
+		var recording tablometadata.Recording
+		if err := json.Unmarshal([]byte(sampleJSON), &recording); err != nil {
+			t.Fatal(err)
+		}
+		if err := recording.Validate(); err != nil {
+			t.Errorf("unexpected validation error %v", err)
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	var recording tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleEpisodeJSON), &recording); err != nil {
+		t.Fatal(err)
+	}
+	var movie tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleMovieJSON), &movie); err != nil {
+		t.Fatal(err)
+	}
+	recording.Airing = movie.Airing
+	recording.RecordedMovie = movie.RecordedMovie
+	recording.RecordedMovie.JSONForClient.ObjectID = 5
+	recording.RecordedEpisode.JSONForClient.Relationships.RecSeason = 1
+	recording.RecordedEpisode.JSONForClient.Relationships.RecSeries = 2
+	recording.RecordedSeason.JSONForClient.Relationships.RecSeries = 3
+	recording.RecordedEpisode.JSONForClient.Video.Duration = -1
+
+	err := recording.Validate()
+	validationErrors, isValidationErrors := err.(tablometadata.ValidationErrors)
+	if !isValidationErrors {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	expectedFields := []string{
+		"",
+		"recEpisode.jsonForClient.video.duration",
+		"recEpisode.jsonForClient.relationships.recSeason",
+		"recEpisode.jsonForClient.relationships.recSeries",
+		"recSeason.jsonForClient.relationships.recSeries",
+		"recMovieAiring.jsonForClient.relationships.recMovie",
+	}
+	if len(validationErrors) != len(expectedFields) {
+		t.Fatalf("expected %d problems, got %d: %v", len(expectedFields), len(validationErrors), err)
+	}
+	for i, field := range expectedFields {
+		if validationErrors[i].Field != field {
+			t.Errorf("problem %d: expected field %q, got %v", i, field, validationErrors[i])
+		}
+	}
+	if !strings.Contains(err.Error(), "both the movie") {
+		t.Errorf("expected the mixed halves to be reported, got %v", err)
+	}
+}
+
+func TestValidateRecordedTypesCheckVideoAndUser(t *testing.T) {
+	var sportEvent tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleSportEventJSON), &sportEvent); err != nil {
+		t.Fatal(err)
+	}
+	sportEvent.RecordedSportEvent.JSONForClient.Video.Duration = -1
+	err := sportEvent.Validate()
+	validationErrors, _ := err.(tablometadata.ValidationErrors)
+	if len(validationErrors) != 1 || validationErrors[0].Field != "recSportEvent.jsonForClient.video.duration" {
+		t.Errorf("expected the negative sport event duration to be reported, got %v", err)
+	}
+
+	var program tablometadata.Recording
+	if err := json.Unmarshal([]byte(sampleProgramJSON), &program); err != nil {
+		t.Fatal(err)
+	}
+	program.RecordedProgram.JSONForClient.User.Position = -3
+	err = program.Validate()
+	validationErrors, _ = err.(tablometadata.ValidationErrors)
+	if len(validationErrors) != 1 || validationErrors[0].Field != "recProgram.jsonForClient.user.position" {
+		t.Errorf("expected the negative program position to be reported, got %v", err)
+	}
+
+	manualProgram := tablometadata.ClientJSON{Type: "recManualProgram", ObjectID: 1, Video: tablometadata.VideoInfo{Duration: -5}}
+	err = manualProgram.Validate()
+	validationErrors, _ = err.(tablometadata.ValidationErrors)
+	if len(validationErrors) != 1 || validationErrors[0].Field != "video.duration" {
+		t.Errorf("expected the negative manual program duration to be reported, got %v", err)
+	}
+}
+
+func TestValidateEmptyRecording(t *testing.T) {
+	if err := (tablometadata.Recording{}).Validate(); err == nil {
+		t.Error("expected an empty recording to be invalid")
+	}
+}